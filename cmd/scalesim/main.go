@@ -12,32 +12,56 @@ import (
 	"time"
 
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 
 	scalesim "github.com/elankath/scaler-simulator"
 	"github.com/elankath/scaler-simulator/engine"
+	"github.com/elankath/scaler-simulator/gardenclient"
 	"github.com/elankath/scaler-simulator/virtualcluster"
 )
 
 func main() {
+	backend := virtualcluster.Backend(os.Getenv("VIRTUAL_CLUSTER_BACKEND"))
+	if len(backend) == 0 {
+		backend = virtualcluster.EnvTestBackend
+	}
+
 	binaryAssetsDir := os.Getenv("BINARY_ASSETS_DIR")
-	if len(binaryAssetsDir) == 0 {
+	if len(binaryAssetsDir) == 0 && backend == virtualcluster.EnvTestBackend {
 		slog.Error("BINARY_ASSETS_DIR env must be set to a dir path containing binaries")
 		os.Exit(1)
 	}
 
+	// shoots with a snapshot in this dir are replayed offline, so the garden envs are only required without it.
+	snapshotDir := os.Getenv("SHOOT_SNAPSHOT_DIR")
+
 	gardenProjectName := os.Getenv("GARDEN_PROJECT_NAME")
-	if len(gardenProjectName) == 0 {
+	if len(gardenProjectName) == 0 && len(snapshotDir) == 0 {
 		slog.Error("GARDEN_PROJECT_NAME env must be set")
 		os.Exit(1)
 	}
 
 	gardenLandscapeName := os.Getenv("GARDEN_LANDSCAPE_NAME")
-	if len(gardenLandscapeName) == 0 {
+	if len(gardenLandscapeName) == 0 && len(snapshotDir) == 0 {
 		slog.Error("GARDEN_LANDSCAPE_NAME env must be set")
 		os.Exit(1)
 	}
 
-	virtualClusterAccess, err := virtualcluster.InitializeAccess(scheme.Scheme, binaryAssetsDir, map[string]string{
+	// the garden kubeconfig is optional, without it live shoots are accessed through gardenctl.
+	var gardenRESTConfig *rest.Config
+	if gardenKubeconfig := os.Getenv("GARDEN_KUBECONFIG"); len(gardenKubeconfig) != 0 {
+		var err error
+		gardenRESTConfig, err = gardenclient.LoadRESTConfig(gardenKubeconfig)
+		if err != nil {
+			slog.Error("cannot load GARDEN_KUBECONFIG", "error", err)
+			os.Exit(2)
+		}
+	}
+
+	// kubeconfigs of stand-in API servers in this dir can be watched instead of shoots.
+	watchKubeconfigDir := os.Getenv("WATCH_KUBECONFIG_DIR")
+
+	virtualClusterAccess, err := virtualcluster.InitializeAccess(scheme.Scheme, backend, binaryAssetsDir, map[string]string{
 		//		"secure-port": apiServerPort, <--TODO: this DOESN'T work..ask maddy on envtest port config
 		//"max-mutating-requests-inflight": "500",
 		//"max-requests-inflight":          "500",
@@ -47,7 +71,7 @@ func main() {
 		os.Exit(3)
 	}
 
-	eng, err := engine.NewEngine(virtualClusterAccess, gardenLandscapeName, gardenProjectName)
+	eng, err := engine.NewEngine(virtualClusterAccess, gardenLandscapeName, gardenProjectName, snapshotDir, gardenRESTConfig, watchKubeconfigDir)
 	if err != nil {
 		slog.Error("cannot initialize simulator engine", "error", err)
 		os.Exit(4)
@@ -61,7 +85,7 @@ func main() {
 	time.Sleep(3 * time.Second)
 	slog.Info("INITIALIZATION COMPLETE!!", "engine-addr", httpServer.Addr)
 
-	go waitForSignalAndShutdown(virtualClusterAccess, httpServer)
+	go waitForSignalAndShutdown(eng, virtualClusterAccess, httpServer)
 
 	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slog.Error("engine cannot listen/serve, SHUTTING DOWN.", "error", err)
@@ -72,7 +96,7 @@ func main() {
 	}
 }
 
-func waitForSignalAndShutdown(virtualAccess scalesim.VirtualClusterAccess, httpServer *http.Server) {
+func waitForSignalAndShutdown(eng scalesim.Engine, virtualAccess scalesim.VirtualClusterAccess, httpServer *http.Server) {
 	slog.Info("Waiting until quit...")
 	quit := make(chan os.Signal, 1)
 
@@ -80,6 +104,7 @@ func waitForSignalAndShutdown(virtualAccess scalesim.VirtualClusterAccess, httpS
 	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
 	s := <-quit
 	slog.Warn("Cleanup and Exit!", "signal", s.String())
+	eng.Shutdown()
 	virtualAccess.Shutdown()
 
 	if err := httpServer.Shutdown(context.Background()); err != nil {
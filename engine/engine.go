@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 	"slices"
 	"sync"
 	"time"
 
+	"github.com/elankath/scaler-simulator/scenarios/scaledown/existingscenario"
 	"github.com/elankath/scaler-simulator/scenarios/scaledown/simplescenario"
 	"github.com/elankath/scaler-simulator/scenarios/scaledown/tscscenario"
 	"github.com/elankath/scaler-simulator/scenarios/score4"
@@ -22,6 +25,12 @@ import (
 	"github.com/elankath/scaler-simulator/scenarios/d"
 
 	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/elankath/scaler-simulator/gardenclient"
 	"github.com/elankath/scaler-simulator/scenarios/a"
@@ -39,11 +48,20 @@ type engine struct {
 	shootAccessMap      map[string]scalesim.ShootAccess
 	gardenLandscapeName string
 	gardenProjectName   string
+	// snapshotDir holds a shoot snapshot sub-directory per shoot name. It is empty if snapshots are disabled.
+	snapshotDir string
+	// gardenRESTConfig is used to access live shoots through clients instead of gardenctl. It is nil if not configured.
+	gardenRESTConfig *rest.Config
+	// watchKubeconfigDir holds the kubeconfigs of stand-in API servers that can be watched instead of shoots. It is
+	// empty if this is disabled.
+	watchKubeconfigDir string
+	// watcher keeps the virtual cluster mirrored with a shoot. It is nil if no shoot is watched.
+	watcher *shootWatcher
 }
 
 var _ scalesim.Engine = (*engine)(nil)
 
-func NewEngine(virtualAccess scalesim.VirtualClusterAccess, gardenLandscapeName string, gardenProjectName string) (scalesim.Engine, error) {
+func NewEngine(virtualAccess scalesim.VirtualClusterAccess, gardenLandscapeName string, gardenProjectName string, snapshotDir string, gardenRESTConfig *rest.Config, watchKubeconfigDir string) (scalesim.Engine, error) {
 	mux := http.NewServeMux()
 
 	engine := &engine{
@@ -51,23 +69,68 @@ func NewEngine(virtualAccess scalesim.VirtualClusterAccess, gardenLandscapeName
 		mux:                 mux,
 		gardenLandscapeName: gardenLandscapeName,
 		gardenProjectName:   gardenProjectName,
+		snapshotDir:         snapshotDir,
+		gardenRESTConfig:    gardenRESTConfig,
+		watchKubeconfigDir:  watchKubeconfigDir,
 		shootAccessMap:      make(map[string]scalesim.ShootAccess),
 	}
 	engine.addRoutes()
 	return engine, nil
 }
 
+// Shutdown stops the watch of a shoot if one is running.
+func (e *engine) Shutdown() {
+	e.mu.Lock()
+	watcher := e.watcher
+	e.watcher = nil
+	e.mu.Unlock()
+	if watcher != nil {
+		watcher.stop()
+	}
+}
+
 func (e *engine) ShootAccess(shootName string) scalesim.ShootAccess {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	access, ok := e.shootAccessMap[shootName]
 	if !ok {
-		access = gardenclient.InitShootAccess(e.gardenLandscapeName, e.gardenProjectName, shootName)
+		access = e.initShootAccess(shootName)
 		e.shootAccessMap[shootName] = access
 	}
 	return access
 }
 
+// initShootAccess replays the snapshot of the shoot if one exists and falls back to the live shoot otherwise.
+func (e *engine) initShootAccess(shootName string) scalesim.ShootAccess {
+	if e.snapshotDir != "" {
+		shootSnapshotDir := filepath.Join(e.snapshotDir, shootName)
+		if _, err := os.Stat(shootSnapshotDir); err == nil {
+			access, err := gardenclient.InitSnapshotShootAccess(shootSnapshotDir)
+			if err == nil {
+				return access
+			}
+			slog.Error("cannot load shoot snapshot, using live shoot instead.", "shoot", shootName, "error", err)
+		}
+	}
+	return e.initLiveShootAccess(shootName)
+}
+
+// initLiveShootAccess talks to the shoot through clients if a garden kubeconfig is configured and through gardenctl otherwise.
+func (e *engine) initLiveShootAccess(shootName string) scalesim.ShootAccess {
+	if e.gardenRESTConfig != nil {
+		access, err := gardenclient.InitClientShootAccess(gardenclient.ClientAccessConfig{
+			GardenRESTConfig: e.gardenRESTConfig,
+			ProjectName:      e.gardenProjectName,
+			ShootName:        shootName,
+		})
+		if err == nil {
+			return access
+		}
+		slog.Error("cannot create client shoot access, using gardenctl instead.", "shoot", shootName, "error", err)
+	}
+	return gardenclient.InitShootAccess(e.gardenLandscapeName, e.gardenProjectName, shootName)
+}
+
 func (e *engine) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	e.mux.ServeHTTP(writer, request)
 }
@@ -79,6 +142,10 @@ func (e *engine) VirtualClusterAccess() scalesim.VirtualClusterAccess {
 func (e *engine) addRoutes() {
 	e.mux.Handle("DELETE /op/virtual-cluster", e.handleClearVirtualCluster())
 	e.mux.Handle("POST /op/sync/{shootName}", e.handleSyncShootNodes())
+	e.mux.Handle("POST /op/snapshot/{shootName}", e.handleSnapshotShoot())
+	e.mux.Handle("POST /op/watch/{shootName}", e.handleStartWatch())
+	e.mux.Handle("GET /op/watch/{shootName}", e.handleGetWatch())
+	e.mux.Handle("DELETE /op/watch/{shootName}", e.handleStopWatch())
 	//mux.Handle("POST /scenario/{id}/{podCount}", handleScenarios(virtualAccess, shootAccess))
 
 	scenarioA := a.New(e)
@@ -105,6 +172,9 @@ func (e *engine) addRoutes() {
 
 	scenarioScaledownTSC := tscscenario.New(e)
 	e.mux.Handle("POST /scenarios/scaledown/"+scenarioScaledownTSC.Name(), scenarioScaledownTSC)
+
+	scenarioScaledownExisting := existingscenario.New(e)
+	e.mux.Handle("POST /scenarios/scaledown/"+scenarioScaledownExisting.Name(), scenarioScaledownExisting)
 }
 
 func (e *engine) handleSyncShootNodes() http.Handler {
@@ -126,18 +196,46 @@ func (e *engine) handleSyncShootNodes() http.Handler {
 	)
 }
 
+func (e *engine) handleSnapshotShoot() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			webutil.SetupSSEWriter(w)
+			shootName := r.PathValue("shootName")
+			if shootName == "" {
+				webutil.HandleShootNameMissing(w)
+				return
+			}
+			if e.snapshotDir == "" {
+				http.Error(w, "SHOOT_SNAPSHOT_DIR env must be set to capture shoot snapshots", http.StatusBadRequest)
+				return
+			}
+			shootSnapshotDir := filepath.Join(e.snapshotDir, shootName)
+			webutil.Log(w, "Capturing snapshot of shoot: "+shootName+" into "+shootSnapshotDir+" ...")
+			liveAccess := e.initLiveShootAccess(shootName)
+			if err := gardenclient.CaptureSnapshot(r.Context(), liveAccess, shootSnapshotDir); err != nil {
+				webutil.InternalError(w, err)
+				return
+			}
+			e.mu.Lock()
+			delete(e.shootAccessMap, shootName)
+			e.mu.Unlock()
+			webutil.Log(w, "Captured snapshot of shoot: "+shootName)
+		},
+	)
+}
+
 func (e *engine) SyncVirtualNodesWithShoot(ctx context.Context, shootName string) error {
 	shootAccess := e.ShootAccess(shootName)
-	nodes, err := shootAccess.GetNodes()
+	nodes, err := shootAccess.GetNodes(ctx)
 	if err != nil {
 		slog.Error("cannot get nodes from shoot.", "shoot", shootName, "error", err)
 		return err
 	}
 
 	for _, node := range nodes {
-		node.Labels["app.kubernetes.io/existing-node"] = "true"
+		node.Labels[simutil.ExistingNodeLabel] = "true"
 		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
-			Key:    "app.kubernetes.io/existing-node-no-schedule",
+			Key:    simutil.ExistingNodeTaintKey,
 			Value:  "NoSchedule",
 			Effect: corev1.TaintEffectNoSchedule,
 		})
@@ -157,6 +255,148 @@ func (e *engine) SyncVirtualNodesWithShoot(ctx context.Context, shootName string
 	return nil
 }
 
+func (e *engine) SyncPriorityClassesWithShoot(ctx context.Context, shootName string) error {
+	priorityClasses, err := e.ShootAccess(shootName).GetPriorityClasses(ctx)
+	if err != nil {
+		slog.Error("cannot get priority classes from shoot.", "shoot", shootName, "error", err)
+		return err
+	}
+	if err = e.VirtualClusterAccess().AddPriorityClasses(ctx, priorityClasses...); err != nil {
+		slog.Error("cannot add priority classes to virtual-cluster.", "error", err)
+		return err
+	}
+	slog.Info("added priority classes to virtual cluster.", "num-priority-classes", len(priorityClasses))
+	return nil
+}
+
+func (e *engine) SyncScheduledPodsWithShoot(ctx context.Context, shootName string) error {
+	pods, err := e.ShootAccess(shootName).GetScheduledPods(ctx)
+	if err != nil {
+		slog.Error("cannot get scheduled pods from shoot.", "shoot", shootName, "error", err)
+		return err
+	}
+	nodes, err := e.VirtualClusterAccess().ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	nodeNames := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		nodeNames[node.Name] = simutil.IsExistingNode(&node)
+	}
+	numPods := 0
+	for _, pod := range pods {
+		podKey := client.ObjectKeyFromObject(&pod)
+		if _, ok := nodeNames[pod.Spec.NodeName]; !ok {
+			slog.Warn("skipping pod bound to node missing in virtual-cluster.", "pod", podKey, "node", pod.Spec.NodeName)
+			continue
+		}
+		existing, err := e.VirtualClusterAccess().GetPod(ctx, podKey)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			if existing.Spec.NodeName == pod.Spec.NodeName {
+				continue
+			}
+			if err = e.VirtualClusterAccess().DeleteK8sObject(ctx, existing); err != nil {
+				return err
+			}
+		}
+		if err = e.VirtualClusterAccess().AddPods(ctx, mirroredPod(&pod)); err != nil {
+			slog.Error("cannot add scheduled pod to virtual-cluster.", "pod", podKey, "error", err)
+			return err
+		}
+		numPods++
+	}
+	for nodeName, existing := range nodeNames {
+		if !existing {
+			continue
+		}
+		if err = e.VirtualClusterAccess().RemoveTaintFromVirtualNode(ctx, nodeName, simutil.ExistingNodeTaintKey); err != nil {
+			slog.Error("cannot un-taint existing node.", "node", nodeName, "error", err)
+			return err
+		}
+	}
+	slog.Info("added scheduled pods to virtual cluster.", "num-pods", numPods)
+	return nil
+}
+
+// mirroredPod returns a copy of the pod of the shoot that is created bound to its node in the virtual cluster. The name
+// is kept, so that pods of the virtual cluster can be matched with the ones of the shoot.
+func mirroredPod(pod *corev1.Pod) corev1.Pod {
+	mirrored := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			Labels:          pod.Labels,
+			Annotations:     pod.Annotations,
+			OwnerReferences: pod.OwnerReferences,
+		},
+		Spec: *pod.Spec.DeepCopy(),
+	}
+	// there is no kubelet that would confirm the deletion of a bound pod.
+	mirrored.Spec.TerminationGracePeriodSeconds = ptr.To(int64(0))
+	return mirrored
+}
+
+func (e *engine) SyncWorkloadWithShoot(ctx context.Context, shootName string) error {
+	if err := e.SyncVirtualNodesWithShoot(ctx, shootName); err != nil {
+		return err
+	}
+	if err := e.SyncPriorityClassesWithShoot(ctx, shootName); err != nil {
+		return err
+	}
+	objs, err := getWorkloadObjects(ctx, e.ShootAccess(shootName))
+	if err != nil {
+		slog.Error("cannot get workload objects from shoot.", "shoot", shootName, "error", err)
+		return err
+	}
+	if err = e.VirtualClusterAccess().ApplyK8sObject(ctx, objs...); err != nil {
+		slog.Error("cannot apply workload objects to virtual-cluster.", "error", err)
+		return err
+	}
+	slog.Info("added workload objects to virtual cluster.", "num-objects", len(objs))
+	return e.SyncScheduledPodsWithShoot(ctx, shootName)
+}
+
+// getWorkloadObjects returns the namespaces, storage objects and PodDisruptionBudgets of the shoot in the order in
+// which they have to be created.
+func getWorkloadObjects(ctx context.Context, shootAccess scalesim.ShootAccess) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	namespaces, err := shootAccess.GetNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs = appendObjects(objs, namespaces)
+	storageClasses, err := shootAccess.GetStorageClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs = appendObjects(objs, storageClasses)
+	pvs, err := shootAccess.GetPersistentVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs = appendObjects(objs, pvs)
+	pvcs, err := shootAccess.GetPersistentVolumeClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs = appendObjects(objs, pvcs)
+	pdbs, err := shootAccess.GetPodDisruptionBudgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return appendObjects(objs, pdbs), nil
+}
+
+func appendObjects[T runtime.Object](objs []runtime.Object, items []T) []runtime.Object {
+	for _, item := range items {
+		objs = append(objs, item)
+	}
+	return objs
+}
+
 func (e *engine) handleClearVirtualCluster() http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -198,7 +438,7 @@ func (e *engine) ScaleWorkerPoolsTillMaxOrNoUnscheduledPods(ctx context.Context,
 				webutil.InternalError(w, err)
 				return totalNodesCreated, err
 			}
-			if err := e.virtualAccess.RemoveAllTaintsFromVirtualNodes(ctx); err != nil {
+			if err := e.virtualAccess.RemoveTaintFromVirtualNodes(ctx, corev1.TaintNodeNotReady); err != nil {
 				webutil.InternalError(w, err)
 				return totalNodesCreated, err
 			}
@@ -225,7 +465,7 @@ func (e *engine) ScaleAllWorkerPoolsTillMax(ctx context.Context, scenarioName st
 			return totalNodesCreated, err
 		}
 		webutil.Log(w, fmt.Sprintf("Created virtual nodes in pool: %q till max: %d", pool.Name, pool.Maximum))
-		if err := e.virtualAccess.RemoveAllTaintsFromVirtualNodes(ctx); err != nil {
+		if err := e.virtualAccess.RemoveTaintFromVirtualNodes(ctx, corev1.TaintNodeNotReady); err != nil {
 			return totalNodesCreated, err
 		}
 		totalNodesCreated += numNodesCreated
@@ -242,7 +482,7 @@ func (e *engine) ScaleWorkerPoolsTillNumZonesMultPoolsMax(ctx context.Context, s
 			return totalNodesCreated, err
 		}
 		webutil.Log(w, fmt.Sprintf("Created virtual nodes in pool %q till max %d", pool.Name, pool.Maximum))
-		if err := e.virtualAccess.RemoveAllTaintsFromVirtualNodes(ctx); err != nil {
+		if err := e.virtualAccess.RemoveTaintFromVirtualNodes(ctx, corev1.TaintNodeNotReady); err != nil {
 			return totalNodesCreated, err
 		}
 		totalNodesCreated += len(pool.Zones) * (int)(pool.Maximum)
@@ -257,7 +497,7 @@ func (e *engine) ScaleWorkerPoolTillMax(ctx context.Context, scenarioName string
 		return numNodesCreated, err
 	}
 	webutil.Log(w, fmt.Sprintf("Created virtual nodes in pool: %q till max: %d", pool.Name, pool.Maximum))
-	if err := e.virtualAccess.RemoveAllTaintsFromVirtualNodes(ctx); err != nil {
+	if err := e.virtualAccess.RemoveTaintFromVirtualNodes(ctx, corev1.TaintNodeNotReady); err != nil {
 		return numNodesCreated, err
 	}
 	return numNodesCreated, nil
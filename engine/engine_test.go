@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/elankath/scaler-simulator/virtualcluster"
+)
+
+// copyTestSnapshot copies the snapshot test data into a snapshot dir for the shoot and returns the snapshot dir.
+func copyTestSnapshot(t *testing.T, shootName string) string {
+	snapshotDir := t.TempDir()
+	shootSnapshotDir := filepath.Join(snapshotDir, shootName)
+	assert.Nil(t, os.Mkdir(shootSnapshotDir, 0o755))
+	files, err := os.ReadDir("../gardenclient/testdata/snapshot")
+	assert.Nil(t, err)
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join("../gardenclient/testdata/snapshot", file.Name()))
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(shootSnapshotDir, file.Name()), data, 0o644))
+	}
+	return snapshotDir
+}
+
+func TestSyncScheduledPodsWithShootKeepsNames(t *testing.T) {
+	ctx := context.Background()
+	shootName := "scenario-score5"
+	snapshotDir := copyTestSnapshot(t, shootName)
+	vca, err := virtualcluster.InitializeAccess(scheme.Scheme, virtualcluster.InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	e, err := NewEngine(vca, "", "", snapshotDir, nil, "")
+	assert.Nil(t, err)
+	assert.Nil(t, e.SyncVirtualNodesWithShoot(ctx, shootName))
+
+	// syncing twice leaves the mirror unchanged.
+	for range 2 {
+		assert.Nil(t, e.SyncScheduledPodsWithShoot(ctx, shootName))
+	}
+	pods, err := vca.ListPods(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "web-7d4b9c-xk2lp", pods[0].Name)
+}
@@ -0,0 +1,768 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/gardenclient"
+	"github.com/elankath/scaler-simulator/recommender"
+	"github.com/elankath/scaler-simulator/simutil"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+// maxWatchHistory is the number of recommendations kept per watched shoot.
+const maxWatchHistory = 100
+
+// watchedResource is a resource of the shoot that is mirrored into the virtual cluster.
+type watchedResource struct {
+	resource  schema.GroupVersionResource
+	newObject func() client.Object
+}
+
+var watchedResources = []watchedResource{
+	{corev1.SchemeGroupVersion.WithResource("namespaces"), func() client.Object { return &corev1.Namespace{} }},
+	{schedulingv1.SchemeGroupVersion.WithResource("priorityclasses"), func() client.Object { return &schedulingv1.PriorityClass{} }},
+	{storagev1.SchemeGroupVersion.WithResource("storageclasses"), func() client.Object { return &storagev1.StorageClass{} }},
+	{corev1.SchemeGroupVersion.WithResource("persistentvolumes"), func() client.Object { return &corev1.PersistentVolume{} }},
+	{corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), func() client.Object { return &corev1.PersistentVolumeClaim{} }},
+	{policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"), func() client.Object { return &policyv1.PodDisruptionBudget{} }},
+	{corev1.SchemeGroupVersion.WithResource("nodes"), func() client.Object { return &corev1.Node{} }},
+	{corev1.SchemeGroupVersion.WithResource("pods"), func() client.Object { return &corev1.Pod{} }},
+}
+
+// watchItem is the key of a changed object in the work queue of a shootWatcher.
+type watchItem struct {
+	resource string
+	key      string
+}
+
+// watchOptions configures the continuous sync of a shoot.
+type watchOptions struct {
+	// kubeconfigName is the name of a kubeconfig file in the watch kubeconfig dir of the engine. The stand-in API
+	// server it points to is watched instead of the shoot if set.
+	kubeconfigName string
+	// scanInterval is how often the unschedulable pods are checked, like --scan-interval of the cluster-autoscaler.
+	scanInterval time.Duration
+	// resyncPeriod is how long the watches run before they are restarted with a fresh rest config, which also
+	// reconciles the virtual cluster with the shoot.
+	resyncPeriod time.Duration
+}
+
+func defaultWatchOptions() watchOptions {
+	return watchOptions{
+		scanInterval: 10 * time.Second,
+		resyncPeriod: 30 * time.Minute,
+	}
+}
+
+func watchOptionsFromRequest(r *http.Request) (watchOptions, error) {
+	opts := defaultWatchOptions()
+	opts.kubeconfigName = webutil.GetStringQueryParam(r, "kubeconfig", "")
+	var err error
+	if opts.scanInterval, err = time.ParseDuration(webutil.GetStringQueryParam(r, "scanInterval", opts.scanInterval.String())); err != nil {
+		return opts, fmt.Errorf("invalid scanInterval: %w", err)
+	}
+	if opts.resyncPeriod, err = time.ParseDuration(webutil.GetStringQueryParam(r, "resyncPeriod", opts.resyncPeriod.String())); err != nil {
+		return opts, fmt.Errorf("invalid resyncPeriod: %w", err)
+	}
+	return opts, nil
+}
+
+// watchRecommendation is the scale-up recommended by a shootWatcher for the pods that were unschedulable at the time.
+type watchRecommendation struct {
+	time            time.Time
+	pendingPods     []string
+	recommendations []recommender.Recommendation
+	err             error
+	// autoscalerScaleUps are the scale-ups of the cluster-autoscaler observed while the pods were unschedulable.
+	autoscalerScaleUps []recommender.Recommendation
+	// comparison with the scale-ups of the cluster-autoscaler, it is nil until the unschedulable pods changed.
+	comparison *recommender.Comparison
+}
+
+func (r watchRecommendation) String() string {
+	result := fmt.Sprintf("%s %d unschedulable pods %v: %s", r.time.Format(time.RFC3339), len(r.pendingPods), r.pendingPods, scaleUpString(r.recommendations))
+	if r.err != nil {
+		return result + ", error: " + r.err.Error()
+	}
+	if r.comparison != nil {
+		result += fmt.Sprintf(", cluster-autoscaler: %s, %s", scaleUpString(r.autoscalerScaleUps), r.comparison)
+	}
+	return result
+}
+
+func scaleUpString(recommendations []recommender.Recommendation) string {
+	if len(recommendations) == 0 {
+		return "no scale-up"
+	}
+	return recommender.RecommendationsString(recommendations)
+}
+
+// shootWatcher mirrors the nodes, pods and the objects they depend on from a shoot into the virtual cluster and
+// recommends a scale-up whenever the set of unschedulable pods of the shoot changes. Changes are applied to the virtual
+// cluster one after another by a single worker, which retries the pods whose node or namespace is not mirrored yet.
+type shootWatcher struct {
+	engine       *engine
+	shootName    string
+	options      watchOptions
+	newClientSet func(ctx context.Context) (kubernetes.Interface, error)
+	queue        workqueue.RateLimitingInterface
+	cancel       context.CancelFunc
+	done         chan struct{}
+	// mu guards the fields below.
+	mu sync.Mutex
+	// informers of the current watch session by resource name.
+	informers   map[string]cache.SharedIndexInformer
+	synced      bool
+	pendingPods map[types.NamespacedName]*corev1.Pod
+	// recommendedPods are the unschedulable pods of the last recommendation.
+	recommendedPods sets.Set[types.NamespacedName]
+	history         []*watchRecommendation
+	// shadow is the last recommendation, which is compared with the scale-ups of the cluster-autoscaler once the
+	// unschedulable pods changed. Only the recommend loop accesses the fields below.
+	shadow *watchRecommendation
+	// shadowPods are the unschedulable pods of the shadow recommendation and shadowCluster is the fork of the virtual
+	// cluster it was recommended in.
+	shadowPods    []corev1.Pod
+	shadowCluster scalesim.VirtualClusterAccess
+	// machineDeployments of the shoot at the last scan.
+	machineDeployments []*machinev1alpha1.MachineDeployment
+}
+
+// forkedEngine is an engine whose virtual cluster is a fork, so that recommendations leave the mirror of the shoot
+// unchanged.
+type forkedEngine struct {
+	scalesim.Engine
+	fork scalesim.VirtualClusterAccess
+}
+
+func (f forkedEngine) VirtualClusterAccess() scalesim.VirtualClusterAccess {
+	return f.fork
+}
+
+func newShootWatcher(e *engine, shootName string, options watchOptions, newClientSet func(ctx context.Context) (kubernetes.Interface, error)) *shootWatcher {
+	return &shootWatcher{
+		engine:       e,
+		shootName:    shootName,
+		options:      options,
+		newClientSet: newClientSet,
+		queue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		done:         make(chan struct{}),
+		pendingPods:  make(map[types.NamespacedName]*corev1.Pod),
+	}
+}
+
+// shootClientSetFunc returns a function that creates a clientSet of the stand-in API server if a kubeconfig is given
+// and of the shoot with a fresh rest config otherwise. Only kubeconfigs inside the watch kubeconfig dir can be used,
+// since the name is sent over HTTP.
+func (e *engine) shootClientSetFunc(shootName, kubeconfigName string) (func(ctx context.Context) (kubernetes.Interface, error), error) {
+	if kubeconfigName != "" {
+		if e.watchKubeconfigDir == "" {
+			return nil, fmt.Errorf("kubeconfig %q cannot be watched without WATCH_KUBECONFIG_DIR set", kubeconfigName)
+		}
+		if !filepath.IsLocal(kubeconfigName) {
+			return nil, fmt.Errorf("kubeconfig %q is not a file in WATCH_KUBECONFIG_DIR", kubeconfigName)
+		}
+		restConfig, err := gardenclient.LoadRESTConfig(filepath.Join(e.watchKubeconfigDir, kubeconfigName))
+		if err != nil {
+			return nil, err
+		}
+		return func(context.Context) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(restConfig)
+		}, nil
+	}
+	access, ok := e.initLiveShootAccess(shootName).(scalesim.ShootRESTConfigAccess)
+	if !ok {
+		return nil, fmt.Errorf("shoot %q can only be watched with GARDEN_KUBECONFIG set or the kubeconfig of a stand-in API server", shootName)
+	}
+	return func(ctx context.Context) (kubernetes.Interface, error) {
+		restConfig, err := access.GetShootRESTConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return kubernetes.NewForConfig(restConfig)
+	}, nil
+}
+
+// startWatch clears the virtual cluster and keeps it mirrored with the shoot until stopWatch is called. Only one shoot
+// can be watched at a time, since the virtual cluster is dedicated to its mirror.
+func (e *engine) startWatch(ctx context.Context, shootName string, options watchOptions) error {
+	newClientSet, err := e.shootClientSetFunc(shootName, options.kubeconfigName)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watcher != nil {
+		return fmt.Errorf("shoot %q is already watched", e.watcher.shootName)
+	}
+	if err = e.virtualAccess.ClearAll(ctx); err != nil {
+		return err
+	}
+	e.watcher = newShootWatcher(e, shootName, options, newClientSet)
+	e.watcher.start()
+	return nil
+}
+
+func (e *engine) stopWatch(shootName string) bool {
+	e.mu.Lock()
+	watcher := e.watcher
+	if watcher == nil || watcher.shootName != shootName {
+		e.mu.Unlock()
+		return false
+	}
+	e.watcher = nil
+	e.mu.Unlock()
+	watcher.stop()
+	return true
+}
+
+func (e *engine) getWatcher(shootName string) *shootWatcher {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watcher == nil || e.watcher.shootName != shootName {
+		return nil
+	}
+	return e.watcher
+}
+
+func (e *engine) handleStartWatch() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			webutil.SetupSSEWriter(w)
+			shootName := r.PathValue("shootName")
+			if shootName == "" {
+				webutil.HandleShootNameMissing(w)
+				return
+			}
+			options, err := watchOptionsFromRequest(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err = e.startWatch(r.Context(), shootName, options); err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			webutil.Log(w, fmt.Sprintf("Watching shoot %s with scan interval %s and resync period %s", shootName, options.scanInterval, options.resyncPeriod))
+		},
+	)
+}
+
+func (e *engine) handleStopWatch() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			shootName := r.PathValue("shootName")
+			if !e.stopWatch(shootName) {
+				http.Error(w, fmt.Sprintf("shoot %q is not watched", shootName), http.StatusNotFound)
+				return
+			}
+			webutil.Log(w, "Stopped watching shoot "+shootName)
+		},
+	)
+}
+
+func (e *engine) handleGetWatch() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			shootName := r.PathValue("shootName")
+			watcher := e.getWatcher(shootName)
+			if watcher == nil {
+				http.Error(w, fmt.Sprintf("shoot %q is not watched", shootName), http.StatusNotFound)
+				return
+			}
+			synced, numPending, history := watcher.status()
+			_, _ = fmt.Fprintf(w, "shoot: %s, synced: %t, unschedulable pods: %d\n", shootName, synced, numPending)
+			for _, recommendation := range history {
+				_, _ = fmt.Fprintln(w, recommendation)
+			}
+		},
+	)
+}
+
+func (w *shootWatcher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	var wg sync.WaitGroup
+	for _, loop := range []func(context.Context){w.run, w.processQueue, w.recommendLoop} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loop(ctx)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(w.done)
+	}()
+	slog.Info("started watching shoot.", "shoot", w.shootName)
+}
+
+func (w *shootWatcher) stop() {
+	w.cancel()
+	w.queue.ShutDown()
+	<-w.done
+	slog.Info("stopped watching shoot.", "shoot", w.shootName)
+}
+
+func (w *shootWatcher) status() (synced bool, numPending int, history []watchRecommendation) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	history = make([]watchRecommendation, 0, len(w.history))
+	for _, recommendation := range w.history {
+		history = append(history, *recommendation)
+	}
+	return w.synced, len(w.pendingPods), history
+}
+
+// run runs watch sessions until the watcher is stopped.
+func (w *shootWatcher) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := w.runSession(ctx); err != nil {
+			slog.Error("watch session of shoot failed, retrying.", "shoot", w.shootName, "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.options.scanInterval):
+			}
+		}
+	}
+}
+
+// runSession watches the shoot until the resync period has passed. Once the watches are synced, the objects of the
+// virtual cluster that no longer exist in the shoot are queued as well, so that they are deleted.
+func (w *shootWatcher) runSession(ctx context.Context) error {
+	clientSet, err := w.newClientSet(ctx)
+	if err != nil {
+		return err
+	}
+	sessionCtx, cancel := context.WithTimeout(ctx, w.options.resyncPeriod)
+	defer cancel()
+	factory := informers.NewSharedInformerFactory(clientSet, 0)
+	defer factory.Shutdown()
+	sessionInformers := make(map[string]cache.SharedIndexInformer, len(watchedResources))
+	for _, watched := range watchedResources {
+		genericInformer, err := factory.ForResource(watched.resource)
+		if err != nil {
+			return err
+		}
+		informer := genericInformer.Informer()
+		if _, err = informer.AddEventHandler(w.eventHandler(watched.resource.Resource)); err != nil {
+			return err
+		}
+		sessionInformers[watched.resource.Resource] = informer
+	}
+	w.mu.Lock()
+	w.informers = sessionInformers
+	w.mu.Unlock()
+	factory.Start(sessionCtx.Done())
+	for informerType, ok := range factory.WaitForCacheSync(sessionCtx.Done()) {
+		if !ok {
+			return fmt.Errorf("cannot sync %v of shoot %q", informerType, w.shootName)
+		}
+	}
+	if err = w.queueStaleObjects(sessionCtx); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.synced = true
+	w.mu.Unlock()
+	slog.Info("synced watches of shoot.", "shoot", w.shootName)
+	<-sessionCtx.Done()
+	return nil
+}
+
+func (w *shootWatcher) eventHandler(resource string) cache.ResourceEventHandler {
+	enqueue := func(obj any) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			slog.Error("cannot get key of watched object.", "resource", resource, "error", err)
+			return
+		}
+		w.queue.Add(watchItem{resource: resource, key: key})
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(_, newObj any) {
+			enqueue(newObj)
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+// queueStaleObjects queues the nodes, pods, PodDisruptionBudgets and PriorityClasses of the virtual cluster, so that
+// those that were deleted in the shoot between two watch sessions are deleted from the virtual cluster too.
+func (w *shootWatcher) queueStaleObjects(ctx context.Context) error {
+	vca := w.engine.VirtualClusterAccess()
+	nodes, err := vca.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		w.queue.Add(watchItem{resource: "nodes", key: node.Name})
+	}
+	pods, err := vca.ListPods(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		w.queue.Add(watchItem{resource: "pods", key: client.ObjectKeyFromObject(&pod).String()})
+	}
+	pdbs, err := vca.ListPodDisruptionBudgets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pdb := range pdbs {
+		w.queue.Add(watchItem{resource: "poddisruptionbudgets", key: client.ObjectKeyFromObject(&pdb).String()})
+	}
+	priorityClasses, err := vca.ListPriorityClasses(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pc := range priorityClasses {
+		w.queue.Add(watchItem{resource: "priorityclasses", key: pc.Name})
+	}
+	return nil
+}
+
+func (w *shootWatcher) processQueue(ctx context.Context) {
+	for {
+		item, shutdown := w.queue.Get()
+		if shutdown {
+			return
+		}
+		if err := w.sync(ctx, item.(watchItem)); err != nil {
+			slog.Debug("cannot mirror object of shoot, retrying.", "shoot", w.shootName, "item", item, "error", err)
+			w.queue.AddRateLimited(item)
+		} else {
+			w.queue.Forget(item)
+		}
+		w.queue.Done(item)
+	}
+}
+
+// sync mirrors the current state of the object in the watch cache into the virtual cluster.
+func (w *shootWatcher) sync(ctx context.Context, item watchItem) error {
+	w.mu.Lock()
+	informer, ok := w.informers[item.resource]
+	w.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("resource %q is not watched", item.resource)
+	}
+	obj, exists, err := informer.GetStore().GetByKey(item.key)
+	if err != nil {
+		return err
+	}
+	vca := w.engine.VirtualClusterAccess()
+	if !exists {
+		w.removePendingPod(item)
+		stub, err := newWatchedObject(item)
+		if err != nil {
+			return err
+		}
+		return vca.DeleteK8sObject(ctx, stub)
+	}
+	switch o := obj.(type) {
+	case *corev1.Node:
+		return w.syncNode(ctx, o.DeepCopy())
+	case *corev1.Pod:
+		return w.syncPod(ctx, o.DeepCopy())
+	case *schedulingv1.PriorityClass:
+		return vca.AddPriorityClasses(ctx, o.DeepCopy())
+	case client.Object:
+		return vca.ApplyK8sObject(ctx, o.DeepCopyObject())
+	}
+	return fmt.Errorf("unexpected object %T of shoot", obj)
+}
+
+func newWatchedObject(item watchItem) (client.Object, error) {
+	for _, watched := range watchedResources {
+		if watched.resource.Resource != item.resource {
+			continue
+		}
+		namespace, name, err := cache.SplitMetaNamespaceKey(item.key)
+		if err != nil {
+			return nil, err
+		}
+		obj := watched.newObject()
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj, nil
+	}
+	return nil, fmt.Errorf("unknown resource %q", item.resource)
+}
+
+// syncNode creates or updates the node in the virtual cluster. Unlike the nodes synced by SyncVirtualNodesWithShoot
+// it is not tainted, since its pods are mirrored as well.
+func (w *shootWatcher) syncNode(ctx context.Context, node *corev1.Node) error {
+	vca := w.engine.VirtualClusterAccess()
+	if node.Labels == nil {
+		node.Labels = make(map[string]string)
+	}
+	node.Labels[simutil.ExistingNodeLabel] = "true"
+	node.ManagedFields = nil
+	existing, err := vca.GetNode(ctx, types.NamespacedName{Name: node.Name})
+	if apierrors.IsNotFound(err) {
+		return vca.AddNodes(ctx, node)
+	}
+	if err != nil {
+		return err
+	}
+	node.UID = existing.UID
+	node.ResourceVersion = existing.ResourceVersion
+	return vca.UpdateNodes(ctx, *node)
+}
+
+// syncPod mirrors the pod if it is bound to a node and tracks it as pending if it is unschedulable. Pods whose node is
+// not mirrored yet fail, so that they are retried.
+func (w *shootWatcher) syncPod(ctx context.Context, pod *corev1.Pod) error {
+	vca := w.engine.VirtualClusterAccess()
+	podKey := client.ObjectKeyFromObject(pod)
+	w.mu.Lock()
+	if isUnschedulable(pod) {
+		w.pendingPods[podKey] = pod
+	} else {
+		delete(w.pendingPods, podKey)
+	}
+	w.mu.Unlock()
+
+	existing, err := vca.GetPod(ctx, podKey)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	switch {
+	case !isBound(pod) && exists:
+		return vca.DeleteK8sObject(ctx, existing)
+	case !isBound(pod) || (exists && existing.Spec.NodeName == pod.Spec.NodeName):
+		return nil
+	}
+	if _, err = vca.GetNode(ctx, types.NamespacedName{Name: pod.Spec.NodeName}); err != nil {
+		return fmt.Errorf("node %q of pod %s is not mirrored yet: %w", pod.Spec.NodeName, podKey, err)
+	}
+	if exists {
+		if err = vca.DeleteK8sObject(ctx, existing); err != nil {
+			return err
+		}
+	}
+	return vca.AddPods(ctx, mirroredPod(pod))
+}
+
+func (w *shootWatcher) removePendingPod(item watchItem) {
+	if item.resource != "pods" {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(item.key)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pendingPods, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// isBound tells whether the pod occupies a node.
+func isBound(pod *corev1.Pod) bool {
+	return pod.Spec.NodeName != "" && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// isUnschedulable tells whether the kube-scheduler failed to find a node for the pod, which makes the
+// cluster-autoscaler consider a scale-up.
+func isUnschedulable(pod *corev1.Pod) bool {
+	if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled {
+			return condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+func (w *shootWatcher) recommendLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.options.scanInterval)
+	defer ticker.Stop()
+	defer w.closeShadow()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.recommendIfPodsChanged(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("cannot recommend scale-up for shoot.", "shoot", w.shootName, "error", err)
+			}
+			if err := w.observeAutoscaler(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("cannot observe scale-ups of cluster-autoscaler of shoot.", "shoot", w.shootName, "error", err)
+			}
+		}
+	}
+}
+
+// recommendIfPodsChanged recommends a scale-up in a fork of the virtual cluster if the unschedulable pods differ from
+// those of the last recommendation, which is compared with the cluster-autoscaler then. Nothing is recommended while
+// the mirror is not in sync.
+func (w *shootWatcher) recommendIfPodsChanged(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.synced || w.queue.Len() > 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	podKeys := sets.KeySet(w.pendingPods)
+	if podKeys.Equal(w.recommendedPods) || (podKeys.Len() == 0 && w.recommendedPods == nil) {
+		w.mu.Unlock()
+		return nil
+	}
+	w.recommendedPods = podKeys
+	pods := make([]corev1.Pod, 0, len(w.pendingPods))
+	for _, pod := range w.pendingPods {
+		pods = append(pods, *pod)
+	}
+	w.mu.Unlock()
+	if err := w.compareShadow(ctx); err != nil {
+		slog.Error("cannot compare recommendation with cluster-autoscaler of shoot.", "shoot", w.shootName, "error", err)
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(pods, func(a, b corev1.Pod) int {
+		return strings.Compare(client.ObjectKeyFromObject(&a).String(), client.ObjectKeyFromObject(&b).String())
+	})
+	result := &watchRecommendation{time: time.Now()}
+	for _, pod := range pods {
+		result.pendingPods = append(result.pendingPods, client.ObjectKeyFromObject(&pod).String())
+	}
+	result.recommendations, result.err = w.recommend(ctx, pods)
+	if result.err == nil {
+		w.shadow = result
+	}
+	slog.Info("recommended scale-up for unschedulable pods of shoot.", "shoot", w.shootName, "recommendation", result.String())
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.history = append(w.history, result)
+	if len(w.history) > maxWatchHistory {
+		w.history = w.history[len(w.history)-maxWatchHistory:]
+	}
+	return result.err
+}
+
+// recommend runs the recommender in a fork of the shadow cluster, which is a fork of the virtual cluster that is kept
+// unchanged for the comparison with the cluster-autoscaler.
+func (w *shootWatcher) recommend(ctx context.Context, pods []corev1.Pod) ([]recommender.Recommendation, error) {
+	shoot, err := w.engine.ShootAccess(w.shootName).GetShootObj(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shadowCluster, err := w.engine.VirtualClusterAccess().Fork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = shadowCluster.InitializeReferenceNodes(ctx); err != nil {
+		shadowCluster.Shutdown()
+		return nil, err
+	}
+	fork, err := shadowCluster.Fork(ctx)
+	if err != nil {
+		shadowCluster.Shutdown()
+		return nil, err
+	}
+	defer fork.Shutdown()
+	recommendations, err := w.newRecommender(shoot, fork).Run(ctx, pods)
+	if err != nil {
+		shadowCluster.Shutdown()
+		return nil, err
+	}
+	w.shadowPods = pods
+	w.shadowCluster = shadowCluster
+	return recommendations, nil
+}
+
+func (w *shootWatcher) newRecommender(shoot *v1beta1.Shoot, fork scalesim.VirtualClusterAccess) *recommender.Recommender {
+	logWriter := webutil.NewSlogWriter("shoot", w.shootName)
+	weights := recommender.StrategyWeights{LeastWaste: 1, LeastCost: 1}
+	return recommender.NewRecommender(forkedEngine{Engine: w.engine, fork: fork}, "watch", "", shoot, nil, weights, nil, logWriter)
+}
+
+// observeAutoscaler attributes the replicas that the machine deployments of the shoot gained since the last scan to
+// the scale-up of the cluster-autoscaler for the pods of the shadow recommendation.
+func (w *shootWatcher) observeAutoscaler(ctx context.Context) error {
+	shootAccess := w.engine.ShootAccess(w.shootName)
+	machineDeployments, err := shootAccess.GetMachineDeployments(ctx)
+	if err != nil {
+		return err
+	}
+	previous := w.machineDeployments
+	w.machineDeployments = machineDeployments
+	if previous == nil || w.shadow == nil {
+		return nil
+	}
+	shoot, err := shootAccess.GetShootObj(ctx)
+	if err != nil {
+		return err
+	}
+	scaleUps := recommender.AutoscalerScaleUps(shoot, previous, machineDeployments)
+	if len(scaleUps) == 0 {
+		return nil
+	}
+	slog.Info("cluster-autoscaler scaled up shoot.", "shoot", w.shootName, "scaleUps", recommender.RecommendationsString(scaleUps))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.shadow.autoscalerScaleUps = append(w.shadow.autoscalerScaleUps, scaleUps...)
+	return nil
+}
+
+// compareShadow compares the shadow recommendation with the scale-ups of the cluster-autoscaler for the same pods in
+// the shadow cluster.
+func (w *shootWatcher) compareShadow(ctx context.Context) error {
+	if w.shadow == nil {
+		return nil
+	}
+	defer w.closeShadow()
+	shoot, err := w.engine.ShootAccess(w.shootName).GetShootObj(ctx)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	autoscalerScaleUps := slices.Clone(w.shadow.autoscalerScaleUps)
+	w.mu.Unlock()
+	comparison, err := w.newRecommender(shoot, w.shadowCluster).Compare(ctx, w.shadowPods, w.shadow.recommendations, autoscalerScaleUps)
+	if err != nil {
+		return err
+	}
+	slog.Info("compared recommendation with cluster-autoscaler of shoot.", "shoot", w.shootName, "comparison", comparison.String())
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.shadow.comparison = &comparison
+	return nil
+}
+
+func (w *shootWatcher) closeShadow() {
+	if w.shadowCluster != nil {
+		w.shadowCluster.Shutdown()
+	}
+	w.shadow, w.shadowPods, w.shadowCluster = nil, nil, nil
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/elankath/scaler-simulator/gardenclient"
+	"github.com/elankath/scaler-simulator/virtualcluster"
+)
+
+func newTestPod(name, nodeName, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{Name: "pause", Image: "registry.k8s.io/pause:3.5", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse(memory)},
+			}}},
+		},
+	}
+}
+
+func TestShootWatcherMirrorsShootAndRecommends(t *testing.T) {
+	ctx := context.Background()
+	shootName := "scenario-score5"
+	snapshotDir := copyTestSnapshot(t, shootName)
+	snapshotAccess, err := gardenclient.InitSnapshotShootAccess(filepath.Join(snapshotDir, shootName))
+	assert.Nil(t, err)
+	nodes, err := snapshotAccess.GetNodes(ctx)
+	assert.Nil(t, err)
+
+	vca, err := virtualcluster.InitializeAccess(scheme.Scheme, virtualcluster.InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	scalesimEngine, err := NewEngine(vca, "", "", snapshotDir, nil, "")
+	assert.Nil(t, err)
+	e := scalesimEngine.(*engine)
+
+	clientSet := fakeclientset.NewSimpleClientset(nodes[0], newTestPod("web", nodes[0].Name, "5Gi"))
+	options := watchOptions{scanInterval: 50 * time.Millisecond, resyncPeriod: time.Hour}
+	e.watcher = newShootWatcher(e, shootName, options, func(context.Context) (kubernetes.Interface, error) {
+		return clientSet, nil
+	})
+	e.watcher.start()
+	defer e.Shutdown()
+
+	assert.Eventually(t, func() bool {
+		_, err := vca.GetPod(ctx, types.NamespacedName{Namespace: "default", Name: "web"})
+		return err == nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	pending := newTestPod("pending", "", "3Gi")
+	pending.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}}
+	_, err = clientSet.CoreV1().Pods("default").Create(ctx, pending, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		_, _, history := e.watcher.status()
+		return len(history) == 1
+	}, 30*time.Second, 100*time.Millisecond)
+	_, _, history := e.watcher.status()
+	assert.Nil(t, history[0].err)
+	assert.Equal(t, []string{"default/pending"}, history[0].pendingPods)
+	assert.Len(t, history[0].recommendations, 1)
+
+	// the snapshot of the shoot does not change, so the cluster-autoscaler did not scale up.
+	assert.Nil(t, clientSet.CoreV1().Pods("default").Delete(ctx, "pending", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		_, _, history := e.watcher.status()
+		return history[0].comparison != nil
+	}, 30*time.Second, 100*time.Millisecond)
+	_, _, history = e.watcher.status()
+	comparison := history[0].comparison
+	assert.False(t, comparison.Agreement)
+	assert.Equal(t, int32(1), comparison.Simulator.NumNodes)
+	assert.Equal(t, 0, comparison.Simulator.UnscheduledPods)
+	assert.Equal(t, 1, comparison.Autoscaler.UnscheduledPods)
+	assert.Greater(t, comparison.CostDelta, 0.0)
+
+	assert.Nil(t, clientSet.CoreV1().Pods("default").Delete(ctx, "web", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		pods, err := vca.ListPods(ctx)
+		return err == nil && len(pods) == 0
+	}, 10*time.Second, 50*time.Millisecond)
+	mirrored, err := vca.GetNode(ctx, types.NamespacedName{Name: nodes[0].Name})
+	assert.Nil(t, err)
+	assert.Empty(t, mirrored.Spec.Taints)
+}
+
+func TestShootClientSetFuncRestrictsKubeconfigs(t *testing.T) {
+	e := &engine{}
+	_, err := e.shootClientSetFunc("s", "kubeconfig.yaml")
+	assert.ErrorContains(t, err, "WATCH_KUBECONFIG_DIR set")
+
+	e.watchKubeconfigDir = t.TempDir()
+	for _, name := range []string{"/etc/kubeconfig.yaml", "../kubeconfig.yaml"} {
+		_, err = e.shootClientSetFunc("s", name)
+		assert.ErrorContains(t, err, "is not a file in WATCH_KUBECONFIG_DIR", name)
+	}
+}
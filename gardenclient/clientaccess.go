@@ -0,0 +1,568 @@
+package gardenclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	authenticationv1alpha1 "github.com/gardener/gardener/pkg/apis/authentication/v1alpha1"
+	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/serutil"
+)
+
+// Clusters accessed by the client based ShootAccess.
+const (
+	GardenCluster = "garden"
+	ShootCluster  = "shoot"
+	SeedCluster   = "seed"
+)
+
+// DefaultTimeout is the timeout of a single call to a cluster if ClientAccessConfig.Timeout is not set.
+const DefaultTimeout = 30 * time.Second
+
+// adminKubeconfigExpiration is the validity of the requested admin kubeconfigs. Clients using them are recreated
+// clientRenewalMargin before they expire.
+const (
+	adminKubeconfigExpiration = time.Hour
+	clientRenewalMargin       = 5 * time.Minute
+)
+
+var clientScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(corev1.AddToScheme(clientScheme))
+	utilruntime.Must(gardencore.AddToScheme(clientScheme))
+	utilruntime.Must(authenticationv1alpha1.AddToScheme(clientScheme))
+	utilruntime.Must(machinev1alpha1.AddToScheme(clientScheme))
+	utilruntime.Must(schedulingv1.AddToScheme(clientScheme))
+	utilruntime.Must(policyv1.AddToScheme(clientScheme))
+	utilruntime.Must(storagev1.AddToScheme(clientScheme))
+}
+
+// ShootAccessError is returned by the client based ShootAccess for every failed call. The wrapped error can be
+// inspected with the apierrors helpers, e.g. apierrors.IsNotFound.
+type ShootAccessError struct {
+	// Cluster is one of GardenCluster, ShootCluster or SeedCluster.
+	Cluster string
+	// Op describes the failed operation.
+	Op  string
+	Err error
+}
+
+func (e *ShootAccessError) Error() string {
+	return fmt.Sprintf("cannot %s in %s cluster: %v", e.Op, e.Cluster, e.Err)
+}
+
+func (e *ShootAccessError) Unwrap() error {
+	return e.Err
+}
+
+// ClientAccessConfig configures a ShootAccess that talks to the garden, shoot and seed clusters directly.
+type ClientAccessConfig struct {
+	// GardenRESTConfig is the config of the garden cluster.
+	GardenRESTConfig *rest.Config
+	// ShootRESTConfig is the config of the shoot cluster. If nil, an admin kubeconfig is requested from the garden.
+	ShootRESTConfig *rest.Config
+	// SeedRESTConfig is the config of the seed cluster hosting the shoot control plane. If nil, an admin kubeconfig
+	// of the managed seed is requested from the garden.
+	SeedRESTConfig *rest.Config
+	ProjectName    string
+	ShootName      string
+	// Timeout bounds every call to one of the clusters. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+type clientAccess struct {
+	config       ClientAccessConfig
+	gardenClient client.Client
+	// mu guards the lazily created clients below.
+	mu          sync.Mutex
+	shootClient *expiringClient
+	seedClient  *expiringClient
+}
+
+// expiringClient is a client whose credentials expire at expiresAt. A zero expiresAt never expires.
+type expiringClient struct {
+	client    client.Client
+	expiresAt time.Time
+}
+
+func (e *expiringClient) usable() bool {
+	return e != nil && (e.expiresAt.IsZero() || time.Now().Add(clientRenewalMargin).Before(e.expiresAt))
+}
+
+var _ scalesim.ShootAccess = (*clientAccess)(nil)
+
+// InitClientShootAccess creates a ShootAccess that uses clients instead of gardenctl and kubectl. Clients for the shoot
+// and seed clusters are created on first use.
+func InitClientShootAccess(config ClientAccessConfig) (scalesim.ShootAccess, error) {
+	if config.GardenRESTConfig == nil {
+		return nil, fmt.Errorf("garden rest config must be set for shoot %q", config.ShootName)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	gardenClient, err := client.New(config.GardenRESTConfig, client.Options{Scheme: clientScheme})
+	if err != nil {
+		return nil, &ShootAccessError{Cluster: GardenCluster, Op: "create client", Err: err}
+	}
+	return &clientAccess{
+		config:       config,
+		gardenClient: gardenClient,
+	}, nil
+}
+
+// LoadRESTConfig loads the rest config from the kubeconfig at the given path.
+func LoadRESTConfig(kubeconfigPath string) (*rest.Config, error) {
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read kubeconfig %q: %w", kubeconfigPath, err)
+	}
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+func (c *clientAccess) ProjectName() string {
+	return c.config.ProjectName
+}
+
+func (c *clientAccess) projectNamespace() string {
+	return "garden-" + c.config.ProjectName
+}
+
+func (c *clientAccess) GetShootObj(ctx context.Context) (*gardencore.Shoot, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	shoot := &gardencore.Shoot{}
+	if err := c.gardenClient.Get(ctx, client.ObjectKey{Namespace: c.projectNamespace(), Name: c.config.ShootName}, shoot); err != nil {
+		return nil, &ShootAccessError{Cluster: GardenCluster, Op: "get shoot " + c.config.ShootName, Err: err}
+	}
+	return shoot, nil
+}
+
+var _ scalesim.ShootRESTConfigAccess = (*clientAccess)(nil)
+
+// GetShootRESTConfig returns the configured rest config of the shoot or requests a new admin kubeconfig, which expires
+// after an hour.
+func (c *clientAccess) GetShootRESTConfig(ctx context.Context) (*rest.Config, error) {
+	if c.config.ShootRESTConfig != nil {
+		return c.config.ShootRESTConfig, nil
+	}
+	restConfig, _, err := c.requestAdminKubeconfig(ctx, c.projectNamespace(), c.config.ShootName)
+	return restConfig, err
+}
+
+// getShootClient returns the client of the shoot cluster. It is created on first use and again shortly before its
+// admin kubeconfig expires.
+func (c *clientAccess) getShootClient(ctx context.Context) (client.Client, error) {
+	return c.getClient(ctx, ShootCluster, &c.shootClient, func(ctx context.Context) (*rest.Config, time.Time, error) {
+		if c.config.ShootRESTConfig != nil {
+			return c.config.ShootRESTConfig, time.Time{}, nil
+		}
+		return c.requestAdminKubeconfig(ctx, c.projectNamespace(), c.config.ShootName)
+	})
+}
+
+// getSeedClient returns the client of the seed cluster like getShootClient. Without an explicit seed config the seed
+// is expected to be a managed seed, i.e. a shoot of the same name in the garden namespace.
+func (c *clientAccess) getSeedClient(ctx context.Context) (client.Client, error) {
+	return c.getClient(ctx, SeedCluster, &c.seedClient, func(ctx context.Context) (*rest.Config, time.Time, error) {
+		if c.config.SeedRESTConfig != nil {
+			return c.config.SeedRESTConfig, time.Time{}, nil
+		}
+		shoot, err := c.GetShootObj(ctx)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if shoot.Status.SeedName == nil {
+			return nil, time.Time{}, &ShootAccessError{Cluster: GardenCluster, Op: "determine seed", Err: fmt.Errorf("shoot %q is not scheduled to a seed", c.config.ShootName)}
+		}
+		return c.requestAdminKubeconfig(ctx, "garden", *shoot.Status.SeedName)
+	})
+}
+
+// getClient returns the cached client if it is still usable and creates a new one otherwise. The config is obtained
+// without holding the lock, so concurrent callers may both create a client and the last one is kept.
+func (c *clientAccess) getClient(ctx context.Context, cluster string, cached **expiringClient, newConfig func(context.Context) (*rest.Config, time.Time, error)) (client.Client, error) {
+	c.mu.Lock()
+	current := *cached
+	c.mu.Unlock()
+	if current.usable() {
+		return current.client, nil
+	}
+	restConfig, expiresAt, err := newConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newClient, err := client.New(restConfig, client.Options{Scheme: clientScheme})
+	if err != nil {
+		return nil, &ShootAccessError{Cluster: cluster, Op: "create client", Err: err}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*cached = &expiringClient{client: newClient, expiresAt: expiresAt}
+	return newClient, nil
+}
+
+// accessError wraps the error of a call to the shoot or seed cluster. The client of the cluster is dropped on
+// Unauthorized errors, so that the next call creates it with fresh credentials.
+func (c *clientAccess) accessError(cluster, op string, err error) error {
+	if apierrors.IsUnauthorized(err) {
+		c.mu.Lock()
+		switch cluster {
+		case ShootCluster:
+			c.shootClient = nil
+		case SeedCluster:
+			c.seedClient = nil
+		}
+		c.mu.Unlock()
+	}
+	return &ShootAccessError{Cluster: cluster, Op: op, Err: err}
+}
+
+// requestAdminKubeconfig returns the rest config of an admin kubeconfig of the shoot and the time it expires.
+func (c *clientAccess) requestAdminKubeconfig(ctx context.Context, namespace, shootName string) (*rest.Config, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	shoot := &gardencore.Shoot{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: shootName}}
+	request := &authenticationv1alpha1.AdminKubeconfigRequest{
+		Spec: authenticationv1alpha1.AdminKubeconfigRequestSpec{ExpirationSeconds: ptr.To(int64(adminKubeconfigExpiration.Seconds()))},
+	}
+	requestTime := time.Now()
+	op := "request admin kubeconfig of shoot " + namespace + "/" + shootName
+	if err := c.gardenClient.SubResource("adminkubeconfig").Create(ctx, shoot, request); err != nil {
+		return nil, time.Time{}, &ShootAccessError{Cluster: GardenCluster, Op: op, Err: err}
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(request.Status.Kubeconfig)
+	if err != nil {
+		return nil, time.Time{}, &ShootAccessError{Cluster: GardenCluster, Op: op, Err: err}
+	}
+	expiresAt := request.Status.ExpirationTimestamp.Time
+	if expiresAt.IsZero() {
+		expiresAt = requestTime.Add(adminKubeconfigExpiration)
+	}
+	return restConfig, expiresAt, nil
+}
+
+// seedNamespace returns the namespace of the shoot control plane in the seed.
+func (c *clientAccess) seedNamespace(ctx context.Context) (string, error) {
+	shoot, err := c.GetShootObj(ctx)
+	if err != nil {
+		return "", err
+	}
+	if shoot.Status.TechnicalID != "" {
+		return shoot.Status.TechnicalID, nil
+	}
+	return fmt.Sprintf("shoot--%s--%s", c.config.ProjectName, c.config.ShootName), nil
+}
+
+func (c *clientAccess) GetNodes(ctx context.Context) ([]*corev1.Node, error) {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	nodeList := &corev1.NodeList{}
+	if err = shootClient.List(ctx, nodeList); err != nil {
+		return nil, c.accessError(ShootCluster, "list nodes", err)
+	}
+	nodes := make([]*corev1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	return nodes, nil
+}
+
+func (c *clientAccess) listPods(ctx context.Context, opts ...client.ListOption) ([]corev1.Pod, error) {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	podList := &corev1.PodList{}
+	if err = shootClient.List(ctx, podList, opts...); err != nil {
+		return nil, c.accessError(ShootCluster, "list pods", err)
+	}
+	return podList.Items, nil
+}
+
+func (c *clientAccess) GetUnscheduledPods(ctx context.Context) ([]corev1.Pod, error) {
+	pods, err := c.listPods(ctx, client.InNamespace("default"))
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		return pod.Spec.NodeName != ""
+	}), nil
+}
+
+func (c *clientAccess) GetScheduledPods(ctx context.Context) ([]corev1.Pod, error) {
+	pods, err := c.listPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		return !isScheduled(&pod)
+	}), nil
+}
+
+func (c *clientAccess) GetDSPods(ctx context.Context) ([]corev1.Pod, error) {
+	pods, err := c.listPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	podsMap := make(map[string]corev1.Pod)
+	for _, pod := range pods {
+		for _, ownerRef := range pod.OwnerReferences {
+			if ownerRef.Kind == "DaemonSet" && pod.GenerateName != "" {
+				podsMap[pod.GenerateName] = pod
+			}
+		}
+	}
+	dsPods := make([]corev1.Pod, 0, len(podsMap))
+	for _, pod := range podsMap {
+		dsPods = append(dsPods, pod)
+	}
+	return dsPods, nil
+}
+
+func (c *clientAccess) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	configMap := &corev1.ConfigMap{}
+	if err = shootClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, c.accessError(ShootCluster, "get config map "+namespace+"/"+name, err)
+	}
+	return configMap, nil
+}
+
+func (c *clientAccess) GetPriorityClasses(ctx context.Context) ([]*schedulingv1.PriorityClass, error) {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	priorityClassList := &schedulingv1.PriorityClassList{}
+	if err = shootClient.List(ctx, priorityClassList); err != nil {
+		return nil, c.accessError(ShootCluster, "list priority classes", err)
+	}
+	priorityClasses := make([]*schedulingv1.PriorityClass, 0, len(priorityClassList.Items))
+	for i := range priorityClassList.Items {
+		priorityClasses = append(priorityClasses, &priorityClassList.Items[i])
+	}
+	return priorityClasses, nil
+}
+
+func (c *clientAccess) GetNamespaces(ctx context.Context) ([]*corev1.Namespace, error) {
+	namespaceList := &corev1.NamespaceList{}
+	if err := c.listShootObjects(ctx, "list namespaces", namespaceList); err != nil {
+		return nil, err
+	}
+	return itemPointers(namespaceList.Items), nil
+}
+
+func (c *clientAccess) GetStorageClasses(ctx context.Context) ([]*storagev1.StorageClass, error) {
+	storageClassList := &storagev1.StorageClassList{}
+	if err := c.listShootObjects(ctx, "list storage classes", storageClassList); err != nil {
+		return nil, err
+	}
+	return itemPointers(storageClassList.Items), nil
+}
+
+func (c *clientAccess) GetPersistentVolumes(ctx context.Context) ([]*corev1.PersistentVolume, error) {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := c.listShootObjects(ctx, "list persistent volumes", pvList); err != nil {
+		return nil, err
+	}
+	return itemPointers(pvList.Items), nil
+}
+
+func (c *clientAccess) GetPersistentVolumeClaims(ctx context.Context) ([]*corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := c.listShootObjects(ctx, "list persistent volume claims", pvcList); err != nil {
+		return nil, err
+	}
+	return itemPointers(pvcList.Items), nil
+}
+
+func (c *clientAccess) GetPodDisruptionBudgets(ctx context.Context) ([]*policyv1.PodDisruptionBudget, error) {
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := c.listShootObjects(ctx, "list pod disruption budgets", pdbList); err != nil {
+		return nil, err
+	}
+	return itemPointers(pdbList.Items), nil
+}
+
+// listShootObjects lists the objects of the given list type from all namespaces of the shoot.
+func (c *clientAccess) listShootObjects(ctx context.Context, op string, list client.ObjectList) error {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	if err = shootClient.List(ctx, list); err != nil {
+		return c.accessError(ShootCluster, op, err)
+	}
+	return nil
+}
+
+func itemPointers[T any](items []T) []*T {
+	pointers := make([]*T, 0, len(items))
+	for i := range items {
+		pointers = append(pointers, &items[i])
+	}
+	return pointers
+}
+
+func (c *clientAccess) GetMachineDeployments(ctx context.Context) ([]*machinev1alpha1.MachineDeployment, error) {
+	seedClient, err := c.getSeedClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := c.seedNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	mcdList := &machinev1alpha1.MachineDeploymentList{}
+	if err = seedClient.List(ctx, mcdList, client.InNamespace(namespace)); err != nil {
+		return nil, c.accessError(SeedCluster, "list machine deployments", err)
+	}
+	machineDeployments := make([]*machinev1alpha1.MachineDeployment, 0, len(mcdList.Items))
+	for i := range mcdList.Items {
+		machineDeployments = append(machineDeployments, &mcdList.Items[i])
+	}
+	return machineDeployments, nil
+}
+
+func (c *clientAccess) ScaleMachineDeployment(ctx context.Context, machineDeploymentName string, replicas int32) error {
+	seedClient, err := c.getSeedClient(ctx)
+	if err != nil {
+		return err
+	}
+	namespace, err := c.seedNamespace(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	mcd := &machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: machineDeploymentName}}
+	patch := client.RawPatch(client.Merge.Type(), []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)))
+	if err = seedClient.Patch(ctx, mcd, patch); err != nil {
+		return c.accessError(SeedCluster, "scale machine deployment "+machineDeploymentName, err)
+	}
+	slog.Info("scaled machine deployment", "name", machineDeploymentName, "replicas", replicas)
+	return nil
+}
+
+func (c *clientAccess) CreatePods(ctx context.Context, filePath string, replicas int) error {
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot read pod spec %q: %w", filePath, err)
+	}
+	pods, err := serutil.DecodeObjects[*corev1.Pod](bytes)
+	if err != nil {
+		return fmt.Errorf("cannot decode pod spec %q: %w", filePath, err)
+	}
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	for i := 0; i < replicas; i++ {
+		for _, pod := range pods {
+			if err = shootClient.Create(ctx, pod.DeepCopy()); err != nil {
+				return c.accessError(ShootCluster, "create pod from "+filePath, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *clientAccess) TaintNodes(ctx context.Context) error {
+	return c.updateNodeTaints(ctx, func(taints []corev1.Taint) []corev1.Taint {
+		if slices.ContainsFunc(taints, isScaleSimTaint) {
+			return taints
+		}
+		return append(taints, corev1.Taint{Key: scaleSimTaintKey, Effect: corev1.TaintEffectNoSchedule})
+	})
+}
+
+func (c *clientAccess) UntaintNodes(ctx context.Context) error {
+	return c.updateNodeTaints(ctx, func(taints []corev1.Taint) []corev1.Taint {
+		return slices.DeleteFunc(taints, isScaleSimTaint)
+	})
+}
+
+func (c *clientAccess) updateNodeTaints(ctx context.Context, updateFn func([]corev1.Taint) []corev1.Taint) error {
+	nodes, err := c.GetNodes(ctx)
+	if err != nil {
+		return err
+	}
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	for _, node := range nodes {
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.Taints = updateFn(node.Spec.Taints)
+		if err = shootClient.Patch(ctx, node, patch); err != nil {
+			return c.accessError(ShootCluster, "update taints of node "+node.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *clientAccess) DeleteAllPods(ctx context.Context) error {
+	shootClient, err := c.getShootClient(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	if err = shootClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("default")); err != nil {
+		return c.accessError(ShootCluster, "delete all pods", err)
+	}
+	return nil
+}
+
+func (c *clientAccess) CleanUp(ctx context.Context) error {
+	err := c.DeleteAllPods(ctx)
+	if err != nil {
+		return err
+	}
+	return c.UntaintNodes(ctx)
+}
@@ -0,0 +1,126 @@
+package gardenclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// newCRD creates a schemaless namespaced CRD, which is enough to store the gardener and MCM objects in envtest.
+func newCRD(group, version, kind, plural string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: plural + "." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: kind, ListKind: kind + "List", Plural: plural},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    version,
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: ptr.To(true)},
+				},
+			}},
+		},
+	}
+}
+
+// TestClientShootAccess runs against an envtest API server that stands in for the garden, shoot and seed clusters.
+func TestClientShootAccess(t *testing.T) {
+	binaryAssetsDir := os.Getenv("BINARY_ASSETS_DIR")
+	if binaryAssetsDir == "" && os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("BINARY_ASSETS_DIR or KUBEBUILDER_ASSETS env must be set to run against envtest")
+	}
+	environment := &envtest.Environment{
+		BinaryAssetsDirectory: binaryAssetsDir,
+		CRDs: []*apiextensionsv1.CustomResourceDefinition{
+			newCRD(gardencore.GroupName, "v1beta1", "Shoot", "shoots"),
+			newCRD(machinev1alpha1.GroupName, "v1alpha1", "MachineDeployment", "machinedeployments"),
+		},
+	}
+	restConfig, err := environment.Start()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { _ = environment.Stop() }()
+
+	ctx := context.Background()
+	c, err := client.New(restConfig, client.Options{Scheme: clientScheme})
+	assert.NoError(t, err)
+	for _, ns := range []string{"garden-dev", "shoot--dev--test"} {
+		assert.NoError(t, c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}))
+	}
+	assert.NoError(t, c.Create(ctx, &gardencore.Shoot{ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "test"}}))
+	assert.NoError(t, c.Create(ctx, &machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--dev--test", Name: "shoot--dev--test-p1-z1"}}))
+	assert.NoError(t, c.Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}))
+	assert.NoError(t, c.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-a"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}},
+	}))
+
+	access, err := InitClientShootAccess(ClientAccessConfig{
+		GardenRESTConfig: restConfig,
+		ShootRESTConfig:  restConfig,
+		SeedRESTConfig:   restConfig,
+		ProjectName:      "dev",
+		ShootName:        "test",
+	})
+	assert.NoError(t, err)
+
+	shoot, err := access.GetShootObj(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", shoot.Name)
+
+	unscheduledPods, err := access.GetUnscheduledPods(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, unscheduledPods, 1)
+	scheduledPods, err := access.GetScheduledPods(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, scheduledPods)
+
+	assert.NoError(t, access.TaintNodes(ctx))
+	nodes, err := access.GetNodes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, []corev1.Taint{{Key: scaleSimTaintKey, Effect: corev1.TaintEffectNoSchedule}}, nodes[0].Spec.Taints)
+	assert.NoError(t, access.UntaintNodes(ctx))
+	nodes, err = access.GetNodes(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, nodes[0].Spec.Taints)
+
+	assert.NoError(t, access.ScaleMachineDeployment(ctx, "shoot--dev--test-p1-z1", 2))
+	machineDeployments, err := access.GetMachineDeployments(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, machineDeployments, 1)
+	assert.Equal(t, int32(2), machineDeployments[0].Spec.Replicas)
+
+	missingAccess, err := InitClientShootAccess(ClientAccessConfig{GardenRESTConfig: restConfig, ProjectName: "dev", ShootName: "missing"})
+	assert.NoError(t, err)
+	_, err = missingAccess.GetShootObj(ctx)
+	var shootAccessErr *ShootAccessError
+	assert.True(t, errors.As(err, &shootAccessErr))
+	assert.Equal(t, GardenCluster, shootAccessErr.Cluster)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestExpiringClientUsable(t *testing.T) {
+	var missing *expiringClient
+	assert.False(t, missing.usable())
+	assert.True(t, (&expiringClient{}).usable())
+	assert.True(t, (&expiringClient{expiresAt: time.Now().Add(time.Hour)}).usable())
+	assert.False(t, (&expiringClient{expiresAt: time.Now().Add(time.Minute)}).usable())
+}
@@ -1,38 +1,48 @@
 package gardenclient
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"time"
 
 	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	"golang.org/x/exp/maps"
 
 	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	scalesim "github.com/elankath/scaler-simulator"
 	"github.com/elankath/scaler-simulator/serutil"
 )
 
+// gardenctlTimeout bounds every gardenctl invocation, which includes targeting the garden and possibly a login.
+const gardenctlTimeout = 2 * time.Minute
+
+// gardenctlWaitDelay is the time given to the shell pipeline to exit after its context is done.
+const gardenctlWaitDelay = 5 * time.Second
+
 type shootAccess struct {
-	landscapeName    string
-	projectName      string
-	shootName        string
-	getShootCmd      *exec.Cmd
-	getNodesCmd      *exec.Cmd
-	getMCDCmd        *exec.Cmd
-	getPodsCmd       *exec.Cmd
-	getAllPodsCmd    *exec.Cmd
-	taintNodesCmd    *exec.Cmd
-	untaintNodesCmd  *exec.Cmd
-	deleteAllPodsCmd *exec.Cmd
-	////shoot
-	//shoot *gardencore.Shoot
-	////Nodes
-	//nodeList []corev1.Node
+	landscapeName         string
+	projectName           string
+	shootName             string
+	cmdEnv                []string
+	getShootCmd           string
+	getNodesCmd           string
+	getMCDCmd             string
+	getPodsCmd            string
+	getAllPodsCmd         string
+	getPriorityClassesCmd string
+	taintNodesCmd         string
+	untaintNodesCmd       string
+	deleteAllPodsCmd      string
 }
 
 func (s *shootAccess) ProjectName() string {
@@ -50,165 +60,106 @@ func InitShootAccess(landscapeName, projectName, shootName string) scalesim.Shoo
 	cmdEnv = append(cmdEnv, fmt.Sprintf("KUBECONFIG=%s", os.Getenv("GARDENCTL_KUBECONFIG")))
 	cmdEnv = append(cmdEnv, "GCTL_SESSION_ID=scalesim")
 
-	shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get shoot %s -oyaml",
-		landscapeName, projectName, shootName)
-	getShootCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	getShootCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get node -oyaml",
-		landscapeName, projectName, shootName)
-	getNodesCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	getNodesCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s --control-plane >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get mcd -oyaml",
-		landscapeName, projectName, shootName)
-	getMCDCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	getMCDCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get pod -oyaml",
-		landscapeName, projectName, shootName)
-	getPodsCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	getPodsCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get pod -A -oyaml",
-		landscapeName, projectName, shootName)
-	getAllPodsCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	getAllPodsCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl taint nodes --all scaleSim:NoSchedule",
-		landscapeName, projectName, shootName)
-	taintNodesCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	taintNodesCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl taint nodes --all scaleSim:NoSchedule-",
-		landscapeName, projectName, shootName)
-	untaintNodesCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	untaintNodesCmd.Env = cmdEnv
-
-	shellCmd = fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl delete pods --all",
-		landscapeName, projectName, shootName)
-	deleteAllPodsCmd := exec.Command("bash", "-l", "-c", shellCmd)
-	deleteAllPodsCmd.Env = cmdEnv
-
 	return &shootAccess{
-		landscapeName:    landscapeName,
-		projectName:      projectName,
-		shootName:        shootName,
-		getShootCmd:      getShootCmd,
-		getNodesCmd:      getNodesCmd,
-		getMCDCmd:        getMCDCmd,
-		getPodsCmd:       getPodsCmd,
-		getAllPodsCmd:    getAllPodsCmd,
-		taintNodesCmd:    taintNodesCmd,
-		untaintNodesCmd:  untaintNodesCmd,
-		deleteAllPodsCmd: deleteAllPodsCmd,
+		landscapeName: landscapeName,
+		projectName:   projectName,
+		shootName:     shootName,
+		cmdEnv:        cmdEnv,
+		getShootCmd: fmt.Sprintf("gardenctl target --garden %s --project %s >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get shoot %s -oyaml",
+			landscapeName, projectName, shootName),
+		getNodesCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get node -oyaml",
+			landscapeName, projectName, shootName),
+		getMCDCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s --control-plane >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get mcd -oyaml",
+			landscapeName, projectName, shootName),
+		getPodsCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get pod -oyaml",
+			landscapeName, projectName, shootName),
+		getAllPodsCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get pod -A -oyaml",
+			landscapeName, projectName, shootName),
+		getPriorityClassesCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get priorityclass -oyaml",
+			landscapeName, projectName, shootName),
+		taintNodesCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl taint nodes --all scaleSim:NoSchedule",
+			landscapeName, projectName, shootName),
+		untaintNodesCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl taint nodes --all scaleSim:NoSchedule-",
+			landscapeName, projectName, shootName),
+		deleteAllPodsCmd: fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl delete pods --all",
+			landscapeName, projectName, shootName),
 	}
 }
 
-func (s *shootAccess) GetShootObj() (*gardencore.Shoot, error) {
-	s.clearCommands()
-	slog.Info("shootAccess.GetShootObj().", "command", s.getShootCmd.String())
-	cmdOutput, err := s.getShootCmd.Output()
+// runCmd runs the given shell pipeline and returns its stdout. The pipeline is killed once the context is done or
+// gardenctlTimeout has passed.
+func (s *shootAccess) runCmd(ctx context.Context, op string, shellCmd string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gardenctlTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "bash", "-l", "-c", shellCmd)
+	cmd.Env = s.cmdEnv
+	cmd.WaitDelay = gardenctlWaitDelay
+	slog.Info("shootAccess."+op+"().", "command", cmd.String())
+	cmdOutput, err := cmd.Output()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		var stderr string
 		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot get shoot obj", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-		return nil, err
+		if errors.As(err, &exitErr) {
+			stderr = string(exitErr.Stderr)
+		}
+		slog.Error("cannot run "+op, "error", err, "stdout", string(cmdOutput), "stderr", stderr)
+		return nil, fmt.Errorf("cannot run %s for shoot %q: %w", op, s.shootName, err)
 	}
-	return serutil.DecodeShoot(cmdOutput)
+	return cmdOutput, nil
 }
 
-func (s *shootAccess) clearCommands() {
-	reset(s.getNodesCmd)
-	reset(s.getShootCmd)
-	reset(s.getMCDCmd)
-	reset(s.getPodsCmd)
-	reset(s.getAllPodsCmd)
-	reset(s.taintNodesCmd)
-	reset(s.untaintNodesCmd)
-	reset(s.deleteAllPodsCmd)
+func (s *shootAccess) GetShootObj(ctx context.Context) (*gardencore.Shoot, error) {
+	cmdOutput, err := s.runCmd(ctx, "GetShootObj", s.getShootCmd)
+	if err != nil {
+		return nil, err
+	}
+	return serutil.DecodeShoot(cmdOutput)
 }
 
-func reset(c *exec.Cmd) {
-	c.Process = nil
-	c.Stdout = nil
-	c.Stderr = nil
-	c.ProcessState = nil
-	c.SysProcAttr = nil
-}
-func (s *shootAccess) GetNodes() ([]*corev1.Node, error) {
-	s.clearCommands()
-	slog.Info("shootAccess.GetNodes().", "command", s.getNodesCmd.String())
-	cmdOutput, err := s.getNodesCmd.Output()
+func (s *shootAccess) GetNodes(ctx context.Context) ([]*corev1.Node, error) {
+	cmdOutput, err := s.runCmd(ctx, "GetNodes", s.getNodesCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot get shoot nodes", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
 		return nil, err
 	}
 	return serutil.DecodeList[*corev1.Node](cmdOutput)
 }
 
-func (s *shootAccess) GetMachineDeployments() ([]*machinev1alpha1.MachineDeployment, error) {
-	s.clearCommands()
-	slog.Info("shootAccess.GetMachineDeployments().", "command", s.getMCDCmd.String())
-	cmdOutput, err := s.getMCDCmd.Output()
+func (s *shootAccess) GetMachineDeployments(ctx context.Context) ([]*machinev1alpha1.MachineDeployment, error) {
+	cmdOutput, err := s.runCmd(ctx, "GetMachineDeployments", s.getMCDCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot get mcd", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
 		return nil, err
 	}
 	return serutil.DecodeList[*machinev1alpha1.MachineDeployment](cmdOutput)
 }
 
-func (s *shootAccess) ScaleMachineDeployment(machineDeploymentName string, replicas int32) error {
-	cmdEnv := os.Environ()
-	cmdEnv = append(cmdEnv, fmt.Sprintf("KUBECONFIG=%s", os.Getenv("GARDENCTL_KUBECONFIG")))
-	cmdEnv = append(cmdEnv, "GCTL_SESSION_ID=scalesim")
-
+func (s *shootAccess) ScaleMachineDeployment(ctx context.Context, machineDeploymentName string, replicas int32) error {
 	shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s --control-plane >&2  && eval $(gardenctl kubectl-env bash) && kubectl scale machinedeployment %s --replicas=%d",
 		s.landscapeName, s.projectName, s.shootName, machineDeploymentName, replicas)
-	scaleMachineDeployment := exec.Command("bash", "-l", "-c", shellCmd)
-	scaleMachineDeployment.Env = cmdEnv
-	cmdOutput, err := scaleMachineDeployment.Output()
-	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot scale mcd object", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-		return err
-	}
-	return nil
+	_, err := s.runCmd(ctx, "ScaleMachineDeployment", shellCmd)
+	return err
 }
 
-func (s *shootAccess) getPods() ([]*corev1.Pod, error) {
-	s.clearCommands()
-	slog.Info("shootAccess.getPods().", "command", s.getPodsCmd.String())
-	cmdOutput, err := s.getPodsCmd.Output()
+func (s *shootAccess) getPods(ctx context.Context) ([]*corev1.Pod, error) {
+	cmdOutput, err := s.runCmd(ctx, "getPods", s.getPodsCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot get shoot pods in the default namespace", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
 		return nil, err
 	}
 	return serutil.DecodeList[*corev1.Pod](cmdOutput)
 }
 
-func (s *shootAccess) getAllPods() ([]*corev1.Pod, error) {
-	s.clearCommands()
-	slog.Info("shootAccess.getAllPods().", "command", s.getAllPodsCmd.String())
-	cmdOutput, err := s.getAllPodsCmd.Output()
+func (s *shootAccess) getAllPods(ctx context.Context) ([]*corev1.Pod, error) {
+	cmdOutput, err := s.runCmd(ctx, "getAllPods", s.getAllPodsCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot get all shoot pods", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
 		return nil, err
 	}
 	return serutil.DecodeList[*corev1.Pod](cmdOutput)
 }
 
-func (s *shootAccess) GetUnscheduledPods() ([]corev1.Pod, error) {
-	pods, err := s.getPods()
+func (s *shootAccess) GetUnscheduledPods(ctx context.Context) ([]corev1.Pod, error) {
+	pods, err := s.getPods(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -221,78 +172,112 @@ func (s *shootAccess) GetUnscheduledPods() ([]corev1.Pod, error) {
 	return unscheduledPods, nil
 }
 
-func (s *shootAccess) CreatePods(filePath string, replicas int) error {
-	for i := 0; i < replicas; i++ {
-		cmdEnv := os.Environ()
-		cmdEnv = append(cmdEnv, fmt.Sprintf("KUBECONFIG=%s", os.Getenv("GARDENCTL_KUBECONFIG")))
-		cmdEnv = append(cmdEnv, "GCTL_SESSION_ID=scalesim")
-		shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2  && eval $(gardenctl kubectl-env bash) && kubectl create -f %s",
-			s.landscapeName, s.projectName, s.shootName, filePath)
-		scaleMachineDeployment := exec.Command("bash", "-l", "-c", shellCmd)
-		scaleMachineDeployment.Env = cmdEnv
-		cmdOutput, err := scaleMachineDeployment.Output()
-		if err != nil {
-			var exitErr *exec.ExitError
-			errors.As(err, &exitErr)
-			slog.Error("cannot scale mcd object", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-			return err
+func (s *shootAccess) GetScheduledPods(ctx context.Context) ([]corev1.Pod, error) {
+	pods, err := s.getAllPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var scheduledPods []corev1.Pod
+	for _, pod := range pods {
+		if isScheduled(pod) {
+			scheduledPods = append(scheduledPods, *pod)
 		}
 	}
-	return nil
+	return scheduledPods, nil
 }
 
-func (s *shootAccess) TaintNodes() error {
-	s.clearCommands()
-	slog.Info("shootAccess.TaintExistingNodes().", "command", s.taintNodesCmd.String())
-	cmdOutput, err := s.taintNodesCmd.Output()
+func (s *shootAccess) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get configmap -n %s %s --ignore-not-found -oyaml",
+		s.landscapeName, s.projectName, s.shootName, namespace, name)
+	cmdOutput, err := s.runCmd(ctx, "GetConfigMap", shellCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot taint shoot nodes", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-		return err
+		return nil, err
 	}
-	return nil
+	configMaps, err := serutil.DecodeObjects[*corev1.ConfigMap](cmdOutput)
+	if err != nil || len(configMaps) == 0 {
+		return nil, err
+	}
+	return configMaps[0], nil
 }
 
-func (s *shootAccess) UntaintNodes() error {
-	s.clearCommands()
-	slog.Info("shootAccess.UntaintExistingNodes().", "command", s.untaintNodesCmd.String())
-	cmdOutput, err := s.untaintNodesCmd.Output()
+func (s *shootAccess) GetPriorityClasses(ctx context.Context) ([]*schedulingv1.PriorityClass, error) {
+	cmdOutput, err := s.runCmd(ctx, "GetPriorityClasses", s.getPriorityClassesCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot untaint shoot nodes", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-		return err
+		return nil, err
 	}
-	return nil
+	return serutil.DecodeList[*schedulingv1.PriorityClass](cmdOutput)
+}
+
+func (s *shootAccess) GetNamespaces(ctx context.Context) ([]*corev1.Namespace, error) {
+	return getShootObjects[*corev1.Namespace](ctx, s, "GetNamespaces", "namespace")
+}
+
+func (s *shootAccess) GetStorageClasses(ctx context.Context) ([]*storagev1.StorageClass, error) {
+	return getShootObjects[*storagev1.StorageClass](ctx, s, "GetStorageClasses", "storageclass")
+}
+
+func (s *shootAccess) GetPersistentVolumes(ctx context.Context) ([]*corev1.PersistentVolume, error) {
+	return getShootObjects[*corev1.PersistentVolume](ctx, s, "GetPersistentVolumes", "persistentvolume")
+}
+
+func (s *shootAccess) GetPersistentVolumeClaims(ctx context.Context) ([]*corev1.PersistentVolumeClaim, error) {
+	return getShootObjects[*corev1.PersistentVolumeClaim](ctx, s, "GetPersistentVolumeClaims", "persistentvolumeclaim")
+}
+
+func (s *shootAccess) GetPodDisruptionBudgets(ctx context.Context) ([]*policyv1.PodDisruptionBudget, error) {
+	return getShootObjects[*policyv1.PodDisruptionBudget](ctx, s, "GetPodDisruptionBudgets", "poddisruptionbudget")
 }
 
-func (s *shootAccess) DeleteAllPods() error {
-	s.clearCommands()
-	slog.Info("shootAccess.DeleteAllPods().", "command", s.deleteAllPodsCmd.String())
-	cmdOutput, err := s.deleteAllPodsCmd.Output()
+// getShootObjects gets the objects of the given resource from all namespaces of the shoot.
+func getShootObjects[T runtime.Object](ctx context.Context, s *shootAccess, op, resource string) ([]T, error) {
+	shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2 &&  eval $(gardenctl kubectl-env bash) && kubectl get %s -A -oyaml",
+		s.landscapeName, s.projectName, s.shootName, resource)
+	cmdOutput, err := s.runCmd(ctx, op, shellCmd)
 	if err != nil {
-		var exitErr *exec.ExitError
-		errors.As(err, &exitErr)
-		slog.Error("cannot delete all pods in the shoot", "error", err, "stdout", string(cmdOutput), "stderr", string(exitErr.Stderr))
-		return err
+		return nil, err
+	}
+	return serutil.DecodeList[T](cmdOutput)
+}
+
+func (s *shootAccess) CreatePods(ctx context.Context, filePath string, replicas int) error {
+	shellCmd := fmt.Sprintf("gardenctl target --garden %s --project %s --shoot %s  >&2  && eval $(gardenctl kubectl-env bash) && kubectl create -f %s",
+		s.landscapeName, s.projectName, s.shootName, filePath)
+	for i := 0; i < replicas; i++ {
+		if _, err := s.runCmd(ctx, "CreatePods", shellCmd); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *shootAccess) CleanUp() error {
-	err := s.DeleteAllPods()
+func (s *shootAccess) TaintNodes(ctx context.Context) error {
+	_, err := s.runCmd(ctx, "TaintNodes", s.taintNodesCmd)
+	return err
+}
+
+func (s *shootAccess) UntaintNodes(ctx context.Context) error {
+	_, err := s.runCmd(ctx, "UntaintNodes", s.untaintNodesCmd)
+	return err
+}
+
+func (s *shootAccess) DeleteAllPods(ctx context.Context) error {
+	_, err := s.runCmd(ctx, "DeleteAllPods", s.deleteAllPodsCmd)
+	return err
+}
+
+func (s *shootAccess) CleanUp(ctx context.Context) error {
+	err := s.DeleteAllPods(ctx)
 	if err != nil {
 		return err
 	}
 
-	return s.UntaintNodes()
+	return s.UntaintNodes(ctx)
 }
 
-func (s *shootAccess) GetDSPods() ([]corev1.Pod, error) {
+func (s *shootAccess) GetDSPods(ctx context.Context) ([]corev1.Pod, error) {
 	podsMap := make(map[string]corev1.Pod)
 
-	allPods, err := s.getAllPods()
+	allPods, err := s.getAllPods(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -309,3 +294,8 @@ func (s *shootAccess) GetDSPods() ([]corev1.Pod, error) {
 
 	return maps.Values(podsMap), nil
 }
+
+// isScheduled tells whether the pod is bound to a node and still occupies its resources.
+func isScheduled(pod *corev1.Pod) bool {
+	return pod.Spec.NodeName != "" && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
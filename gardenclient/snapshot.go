@@ -0,0 +1,473 @@
+package gardenclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/serutil"
+)
+
+// Files of a shoot snapshot directory. Every file except the shoot file is a multi-document yaml.
+const (
+	ShootFileName              = "shoot.yaml"
+	NodesFileName              = "nodes.yaml"
+	UnscheduledPodsFileName    = "unscheduled-pods.yaml"
+	DaemonSetPodsFileName      = "daemonset-pods.yaml"
+	MachineDeploymentsFileName = "machinedeployments.yaml"
+	// ConfigMapsFileName is optional and holds the config maps that the simulator reads from the shoot.
+	ConfigMapsFileName = "configmaps.yaml"
+	// PriorityClassesFileName is optional and holds the PriorityClasses of the shoot.
+	PriorityClassesFileName = "priorityclasses.yaml"
+	// ScheduledPodsFileName is optional and holds the pods of the shoot that are bound to a node.
+	ScheduledPodsFileName = "scheduled-pods.yaml"
+	// The workload files are optional and hold the objects of the shoot that the scheduling of its pods depends on.
+	NamespacesFileName             = "namespaces.yaml"
+	StorageClassesFileName         = "storageclasses.yaml"
+	PersistentVolumesFileName      = "persistentvolumes.yaml"
+	PersistentVolumeClaimsFileName = "persistentvolumeclaims.yaml"
+	PodDisruptionBudgetsFileName   = "poddisruptionbudgets.yaml"
+)
+
+// Config map of the cluster-autoscaler priority expander in the shoot.
+const (
+	PriorityExpanderConfigMapNamespace = "kube-system"
+	PriorityExpanderConfigMapName      = "cluster-autoscaler-priority-expander"
+)
+
+const scaleSimTaintKey = "scaleSim"
+
+// snapshotAccess is a ShootAccess that replays a shoot snapshot captured by CaptureSnapshot. The snapshot is loaded
+// once and all changes like tainting nodes or creating pods are only applied in memory, so that scenarios can run
+// without access to the Gardener landscape.
+type snapshotAccess struct {
+	snapshotDir        string
+	projectName        string
+	mu                 sync.Mutex
+	shoot              *gardencore.Shoot
+	nodes              []*corev1.Node
+	unscheduledPods    []corev1.Pod
+	scheduledPods      []corev1.Pod
+	dsPods             []corev1.Pod
+	machineDeployments []*machinev1alpha1.MachineDeployment
+	configMaps         []*corev1.ConfigMap
+	priorityClasses    []*schedulingv1.PriorityClass
+	namespaces         []*corev1.Namespace
+	storageClasses     []*storagev1.StorageClass
+	pvs                []*corev1.PersistentVolume
+	pvcs               []*corev1.PersistentVolumeClaim
+	pdbs               []*policyv1.PodDisruptionBudget
+}
+
+var _ scalesim.ShootAccess = (*snapshotAccess)(nil)
+
+// InitSnapshotShootAccess loads the shoot snapshot in the given directory.
+func InitSnapshotShootAccess(snapshotDir string) (scalesim.ShootAccess, error) {
+	shootBytes, err := os.ReadFile(filepath.Join(snapshotDir, ShootFileName))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read shoot of snapshot %q: %w", snapshotDir, err)
+	}
+	shoot, err := serutil.DecodeShoot(shootBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode shoot of snapshot %q: %w", snapshotDir, err)
+	}
+	s := &snapshotAccess{
+		snapshotDir: snapshotDir,
+		projectName: strings.TrimPrefix(shoot.Namespace, "garden-"),
+		shoot:       shoot,
+	}
+	if s.nodes, err = readSnapshotFile[*corev1.Node](snapshotDir, NodesFileName); err != nil {
+		return nil, err
+	}
+	unscheduledPods, err := readSnapshotFile[*corev1.Pod](snapshotDir, UnscheduledPodsFileName)
+	if err != nil {
+		return nil, err
+	}
+	s.unscheduledPods = derefPods(unscheduledPods)
+	dsPods, err := readSnapshotFile[*corev1.Pod](snapshotDir, DaemonSetPodsFileName)
+	if err != nil {
+		return nil, err
+	}
+	s.dsPods = derefPods(dsPods)
+	if s.machineDeployments, err = readSnapshotFile[*machinev1alpha1.MachineDeployment](snapshotDir, MachineDeploymentsFileName); err != nil {
+		return nil, err
+	}
+	if s.configMaps, err = readOptionalSnapshotFile[*corev1.ConfigMap](snapshotDir, ConfigMapsFileName); err != nil {
+		return nil, err
+	}
+	scheduledPods, err := readOptionalSnapshotFile[*corev1.Pod](snapshotDir, ScheduledPodsFileName)
+	if err != nil {
+		return nil, err
+	}
+	s.scheduledPods = derefPods(scheduledPods)
+	if s.priorityClasses, err = readOptionalSnapshotFile[*schedulingv1.PriorityClass](snapshotDir, PriorityClassesFileName); err != nil {
+		return nil, err
+	}
+	if s.namespaces, err = readOptionalSnapshotFile[*corev1.Namespace](snapshotDir, NamespacesFileName); err != nil {
+		return nil, err
+	}
+	if s.storageClasses, err = readOptionalSnapshotFile[*storagev1.StorageClass](snapshotDir, StorageClassesFileName); err != nil {
+		return nil, err
+	}
+	if s.pvs, err = readOptionalSnapshotFile[*corev1.PersistentVolume](snapshotDir, PersistentVolumesFileName); err != nil {
+		return nil, err
+	}
+	if s.pvcs, err = readOptionalSnapshotFile[*corev1.PersistentVolumeClaim](snapshotDir, PersistentVolumeClaimsFileName); err != nil {
+		return nil, err
+	}
+	if s.pdbs, err = readOptionalSnapshotFile[*policyv1.PodDisruptionBudget](snapshotDir, PodDisruptionBudgetsFileName); err != nil {
+		return nil, err
+	}
+	slog.Info("loaded shoot snapshot", "dir", snapshotDir, "shoot", shoot.Name, "nodes", len(s.nodes), "unscheduledPods", len(s.unscheduledPods))
+	return s, nil
+}
+
+// CaptureSnapshot writes the current state of the shoot of the given ShootAccess into the given directory in the
+// format read by InitSnapshotShootAccess.
+func CaptureSnapshot(ctx context.Context, shootAccess scalesim.ShootAccess, snapshotDir string) error {
+	shoot, err := shootAccess.GetShootObj(ctx)
+	if err != nil {
+		return err
+	}
+	nodes, err := shootAccess.GetNodes(ctx)
+	if err != nil {
+		return err
+	}
+	unscheduledPods, err := shootAccess.GetUnscheduledPods(ctx)
+	if err != nil {
+		return err
+	}
+	scheduledPods, err := shootAccess.GetScheduledPods(ctx)
+	if err != nil {
+		return err
+	}
+	dsPods, err := shootAccess.GetDSPods(ctx)
+	if err != nil {
+		return err
+	}
+	machineDeployments, err := shootAccess.GetMachineDeployments(ctx)
+	if err != nil {
+		return err
+	}
+	var configMaps []*corev1.ConfigMap
+	priorityExpanderConfigMap, err := shootAccess.GetConfigMap(ctx, PriorityExpanderConfigMapNamespace, PriorityExpanderConfigMapName)
+	if err != nil {
+		return err
+	}
+	if priorityExpanderConfigMap != nil {
+		configMaps = append(configMaps, priorityExpanderConfigMap)
+	}
+	priorityClasses, err := shootAccess.GetPriorityClasses(ctx)
+	if err != nil {
+		return err
+	}
+	namespaces, err := shootAccess.GetNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	storageClasses, err := shootAccess.GetStorageClasses(ctx)
+	if err != nil {
+		return err
+	}
+	pvs, err := shootAccess.GetPersistentVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	pvcs, err := shootAccess.GetPersistentVolumeClaims(ctx)
+	if err != nil {
+		return err
+	}
+	pdbs, err := shootAccess.GetPodDisruptionBudgets(ctx)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(snapshotDir, 0755); err != nil {
+		return fmt.Errorf("cannot create snapshot dir %q: %w", snapshotDir, err)
+	}
+	return errors.Join(
+		writeOptionalSnapshotFile(snapshotDir, ConfigMapsFileName, configMaps),
+		writeOptionalSnapshotFile(snapshotDir, ScheduledPodsFileName, refPods(scheduledPods)),
+		writeOptionalSnapshotFile(snapshotDir, PriorityClassesFileName, priorityClasses),
+		writeOptionalSnapshotFile(snapshotDir, NamespacesFileName, namespaces),
+		writeOptionalSnapshotFile(snapshotDir, StorageClassesFileName, storageClasses),
+		writeOptionalSnapshotFile(snapshotDir, PersistentVolumesFileName, pvs),
+		writeOptionalSnapshotFile(snapshotDir, PersistentVolumeClaimsFileName, pvcs),
+		writeOptionalSnapshotFile(snapshotDir, PodDisruptionBudgetsFileName, pdbs),
+		writeSnapshotFile(snapshotDir, ShootFileName, []*gardencore.Shoot{shoot}),
+		writeSnapshotFile(snapshotDir, NodesFileName, nodes),
+		writeSnapshotFile(snapshotDir, UnscheduledPodsFileName, refPods(unscheduledPods)),
+		writeSnapshotFile(snapshotDir, DaemonSetPodsFileName, refPods(dsPods)),
+		writeSnapshotFile(snapshotDir, MachineDeploymentsFileName, machineDeployments),
+	)
+}
+
+func readSnapshotFile[T runtime.Object](snapshotDir, fileName string) ([]T, error) {
+	bytes, err := os.ReadFile(filepath.Join(snapshotDir, fileName))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s of snapshot %q: %w", fileName, snapshotDir, err)
+	}
+	objs, err := serutil.DecodeObjects[T](bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s of snapshot %q: %w", fileName, snapshotDir, err)
+	}
+	return objs, nil
+}
+
+// readOptionalSnapshotFile reads a snapshot file that is missing in snapshots of older versions or without objects.
+func readOptionalSnapshotFile[T runtime.Object](snapshotDir, fileName string) ([]T, error) {
+	if _, err := os.Stat(filepath.Join(snapshotDir, fileName)); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return readSnapshotFile[T](snapshotDir, fileName)
+}
+
+// writeOptionalSnapshotFile writes a snapshot file unless there are no objects. Then the file of a previous capture is
+// removed, so that its objects are not replayed.
+func writeOptionalSnapshotFile[T interface {
+	runtime.Object
+	metav1.Object
+}](snapshotDir, fileName string, objs []T) error {
+	if len(objs) == 0 {
+		if err := os.Remove(filepath.Join(snapshotDir, fileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot remove %s of snapshot %q: %w", fileName, snapshotDir, err)
+		}
+		return nil
+	}
+	return writeSnapshotFile(snapshotDir, fileName, objs)
+}
+
+func writeSnapshotFile[T interface {
+	runtime.Object
+	metav1.Object
+}](snapshotDir, fileName string, objs []T) error {
+	for _, obj := range objs {
+		obj.SetManagedFields(nil)
+	}
+	bytes, err := serutil.EncodeObjects(objs)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s of snapshot %q: %w", fileName, snapshotDir, err)
+	}
+	if err = os.WriteFile(filepath.Join(snapshotDir, fileName), bytes, 0644); err != nil {
+		return fmt.Errorf("cannot write %s of snapshot %q: %w", fileName, snapshotDir, err)
+	}
+	return nil
+}
+
+func refPods(pods []corev1.Pod) []*corev1.Pod {
+	podRefs := make([]*corev1.Pod, 0, len(pods))
+	for i := range pods {
+		podRefs = append(podRefs, &pods[i])
+	}
+	return podRefs
+}
+
+func derefPods(pods []*corev1.Pod) []corev1.Pod {
+	podValues := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		podValues = append(podValues, *pod)
+	}
+	return podValues
+}
+
+func deepCopies[T interface{ DeepCopy() T }](objs []T) []T {
+	copies := make([]T, 0, len(objs))
+	for _, obj := range objs {
+		copies = append(copies, obj.DeepCopy())
+	}
+	return copies
+}
+
+func copyPods(pods []corev1.Pod) []corev1.Pod {
+	podCopies := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		podCopies = append(podCopies, *pod.DeepCopy())
+	}
+	return podCopies
+}
+
+func (s *snapshotAccess) ProjectName() string {
+	return s.projectName
+}
+
+func (s *snapshotAccess) GetShootObj(_ context.Context) (*gardencore.Shoot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shoot.DeepCopy(), nil
+}
+
+func (s *snapshotAccess) GetNodes(_ context.Context) ([]*corev1.Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nodes := make([]*corev1.Node, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nodes = append(nodes, node.DeepCopy())
+	}
+	return nodes, nil
+}
+
+func (s *snapshotAccess) GetUnscheduledPods(_ context.Context) ([]corev1.Pod, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyPods(s.unscheduledPods), nil
+}
+
+func (s *snapshotAccess) GetScheduledPods(_ context.Context) ([]corev1.Pod, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyPods(s.scheduledPods), nil
+}
+
+func (s *snapshotAccess) GetNamespaces(_ context.Context) ([]*corev1.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.namespaces), nil
+}
+
+func (s *snapshotAccess) GetStorageClasses(_ context.Context) ([]*storagev1.StorageClass, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.storageClasses), nil
+}
+
+func (s *snapshotAccess) GetPersistentVolumes(_ context.Context) ([]*corev1.PersistentVolume, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.pvs), nil
+}
+
+func (s *snapshotAccess) GetPersistentVolumeClaims(_ context.Context) ([]*corev1.PersistentVolumeClaim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.pvcs), nil
+}
+
+func (s *snapshotAccess) GetPodDisruptionBudgets(_ context.Context) ([]*policyv1.PodDisruptionBudget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.pdbs), nil
+}
+
+func (s *snapshotAccess) GetDSPods(_ context.Context) ([]corev1.Pod, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyPods(s.dsPods), nil
+}
+
+func (s *snapshotAccess) GetConfigMap(_ context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, configMap := range s.configMaps {
+		if configMap.Namespace == namespace && configMap.Name == name {
+			return configMap.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *snapshotAccess) GetPriorityClasses(_ context.Context) ([]*schedulingv1.PriorityClass, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.priorityClasses), nil
+}
+
+func (s *snapshotAccess) GetMachineDeployments(_ context.Context) ([]*machinev1alpha1.MachineDeployment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deepCopies(s.machineDeployments), nil
+}
+
+func (s *snapshotAccess) ScaleMachineDeployment(_ context.Context, machineDeploymentName string, replicas int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, mcd := range s.machineDeployments {
+		if mcd.Name == machineDeploymentName {
+			mcd.Spec.Replicas = replicas
+			return nil
+		}
+	}
+	return fmt.Errorf("machine deployment %q not found in snapshot %q", machineDeploymentName, s.snapshotDir)
+}
+
+func (s *snapshotAccess) CreatePods(_ context.Context, filePath string, replicas int) error {
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot read pod spec %q: %w", filePath, err)
+	}
+	pods, err := serutil.DecodeObjects[*corev1.Pod](bytes)
+	if err != nil {
+		return fmt.Errorf("cannot decode pod spec %q: %w", filePath, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < replicas; i++ {
+		for _, pod := range pods {
+			podCopy := pod.DeepCopy()
+			if podCopy.GenerateName != "" {
+				podCopy.Name = podCopy.GenerateName + rand.String(5)
+			}
+			if podCopy.Namespace == "" {
+				podCopy.Namespace = "default"
+			}
+			s.unscheduledPods = append(s.unscheduledPods, *podCopy)
+		}
+	}
+	return nil
+}
+
+func (s *snapshotAccess) TaintNodes(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, node := range s.nodes {
+		if !slices.ContainsFunc(node.Spec.Taints, isScaleSimTaint) {
+			node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: scaleSimTaintKey, Effect: corev1.TaintEffectNoSchedule})
+		}
+	}
+	return nil
+}
+
+func (s *snapshotAccess) UntaintNodes(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, node := range s.nodes {
+		node.Spec.Taints = slices.DeleteFunc(node.Spec.Taints, isScaleSimTaint)
+	}
+	return nil
+}
+
+func isScaleSimTaint(taint corev1.Taint) bool {
+	return taint.Key == scaleSimTaintKey && taint.Effect == corev1.TaintEffectNoSchedule
+}
+
+func (s *snapshotAccess) DeleteAllPods(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unscheduledPods = slices.DeleteFunc(s.unscheduledPods, func(pod corev1.Pod) bool {
+		return pod.Namespace == "default"
+	})
+	return nil
+}
+
+func (s *snapshotAccess) CleanUp(ctx context.Context) error {
+	err := s.DeleteAllPods(ctx)
+	if err != nil {
+		return err
+	}
+	return s.UntaintNodes(ctx)
+}
@@ -0,0 +1,88 @@
+package gardenclient
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotShootAccessRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	access, err := InitSnapshotShootAccess("testdata/snapshot")
+	assert.Nil(t, err)
+	assert.Equal(t, "i034796", access.ProjectName())
+
+	assert.Nil(t, access.TaintNodes(ctx))
+	assert.Nil(t, access.ScaleMachineDeployment(ctx, "shoot--i034796--scenario-score5-p1-z1", 2))
+
+	snapshotDir := t.TempDir()
+	assert.Nil(t, CaptureSnapshot(ctx, access, snapshotDir))
+	replayed, err := InitSnapshotShootAccess(snapshotDir)
+	assert.Nil(t, err)
+
+	shoot, err := replayed.GetShootObj(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "scenario-score5", shoot.Name)
+	assert.Len(t, shoot.Spec.Provider.Workers, 1)
+
+	nodes, err := replayed.GetNodes(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Len(t, nodes[0].Spec.Taints, 1)
+	assert.Equal(t, "p1", nodes[0].Labels["worker.gardener.cloud/pool"])
+
+	unscheduledPods, err := replayed.GetUnscheduledPods(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, unscheduledPods, 2)
+	dsPods, err := replayed.GetDSPods(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, dsPods, 1)
+	scheduledPods, err := replayed.GetScheduledPods(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, scheduledPods, 1)
+	assert.Equal(t, nodes[0].Name, scheduledPods[0].Spec.NodeName)
+
+	machineDeployments, err := replayed.GetMachineDeployments(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), machineDeployments[0].Spec.Replicas)
+
+	configMap, err := replayed.GetConfigMap(ctx, PriorityExpanderConfigMapNamespace, PriorityExpanderConfigMapName)
+	assert.Nil(t, err)
+	assert.Contains(t, configMap.Data["priorities"], "p1")
+	configMap, err = replayed.GetConfigMap(ctx, "default", "missing")
+	assert.Nil(t, err)
+	assert.Nil(t, configMap)
+
+	priorityClasses, err := replayed.GetPriorityClasses(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, priorityClasses, 2)
+	assert.Equal(t, int32(-20), priorityClasses[1].Value)
+
+	namespaces, err := replayed.GetNamespaces(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, namespaces, 2)
+	pdbs, err := replayed.GetPodDisruptionBudgets(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, pdbs, 1)
+	assert.Equal(t, int32(0), pdbs[0].Status.DisruptionsAllowed)
+	storageClasses, err := replayed.GetStorageClasses(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, storageClasses)
+
+	// objects that are gone in a later capture are not replayed.
+	replayed.(*snapshotAccess).pdbs = nil
+	assert.Nil(t, CaptureSnapshot(ctx, replayed, snapshotDir))
+	assert.NoFileExists(t, filepath.Join(snapshotDir, PodDisruptionBudgetsFileName))
+	recaptured, err := InitSnapshotShootAccess(snapshotDir)
+	assert.Nil(t, err)
+	pdbs, err = recaptured.GetPodDisruptionBudgets(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, pdbs)
+
+	assert.Nil(t, replayed.CleanUp(ctx))
+	unscheduledPods, err = replayed.GetUnscheduledPods(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, unscheduledPods)
+}
@@ -10,7 +10,37 @@ require (
 	sigs.k8s.io/controller-runtime v0.17.1
 )
 
-require github.com/samber/lo v1.39.0
+require (
+	github.com/samber/lo v1.39.0
+	k8s.io/component-helpers v0.29.1
+	k8s.io/kubernetes v1.29.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/blang/semver/v4 v4.0.0 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/evanphx/json-patch v5.6.0+incompatible // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/matttproud/golang_protobuf_extensions/v2 v2.0.0 // indirect
+	github.com/moby/sys/mountinfo v0.6.2 // indirect
+	github.com/opencontainers/selinux v1.11.0 // indirect
+	github.com/prometheus/client_golang v1.18.0 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.45.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230822172742-b8732ec3820d // indirect
+	google.golang.org/grpc v1.58.3 // indirect
+	k8s.io/apiserver v0.29.1 // indirect
+	k8s.io/cloud-provider v0.0.0 // indirect
+	k8s.io/component-base v0.29.1 // indirect
+	k8s.io/csi-translation-lib v0.0.0 // indirect
+	k8s.io/dynamic-resource-allocation v0.0.0 // indirect
+	k8s.io/kube-scheduler v0.0.0 // indirect
+	k8s.io/mount-utils v0.0.0 // indirect
+)
 
 require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -52,11 +82,67 @@ require (
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	k8s.io/api v0.29.1
-	k8s.io/apiextensions-apiserver v0.29.0 // indirect
+	k8s.io/apiextensions-apiserver v0.29.0
 	k8s.io/klog/v2 v2.110.1 // indirect
 	k8s.io/kube-openapi v0.0.0-20231010175941-2dd684a91f00 // indirect
 	k8s.io/utils v0.0.0-20240102154912-e7106e64919e
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
+	sigs.k8s.io/yaml v1.4.0
 )
+
+replace k8s.io/api => k8s.io/api v0.29.1
+
+replace k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.29.1
+
+replace k8s.io/apimachinery => k8s.io/apimachinery v0.29.1
+
+replace k8s.io/apiserver => k8s.io/apiserver v0.29.1
+
+replace k8s.io/cli-runtime => k8s.io/cli-runtime v0.29.1
+
+replace k8s.io/client-go => k8s.io/client-go v0.29.1
+
+replace k8s.io/cloud-provider => k8s.io/cloud-provider v0.29.1
+
+replace k8s.io/cluster-bootstrap => k8s.io/cluster-bootstrap v0.29.1
+
+replace k8s.io/code-generator => k8s.io/code-generator v0.29.1
+
+replace k8s.io/component-base => k8s.io/component-base v0.29.1
+
+replace k8s.io/component-helpers => k8s.io/component-helpers v0.29.1
+
+replace k8s.io/controller-manager => k8s.io/controller-manager v0.29.1
+
+replace k8s.io/cri-api => k8s.io/cri-api v0.29.1
+
+replace k8s.io/csi-translation-lib => k8s.io/csi-translation-lib v0.29.1
+
+replace k8s.io/dynamic-resource-allocation => k8s.io/dynamic-resource-allocation v0.29.1
+
+replace k8s.io/endpointslice => k8s.io/endpointslice v0.29.1
+
+replace k8s.io/kms => k8s.io/kms v0.29.1
+
+replace k8s.io/kube-aggregator => k8s.io/kube-aggregator v0.29.1
+
+replace k8s.io/kube-controller-manager => k8s.io/kube-controller-manager v0.29.1
+
+replace k8s.io/kube-proxy => k8s.io/kube-proxy v0.29.1
+
+replace k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.29.1
+
+replace k8s.io/kubectl => k8s.io/kubectl v0.29.1
+
+replace k8s.io/kubelet => k8s.io/kubelet v0.29.1
+
+replace k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.29.1
+
+replace k8s.io/metrics => k8s.io/metrics v0.29.1
+
+replace k8s.io/mount-utils => k8s.io/mount-utils v0.29.1
+
+replace k8s.io/pod-security-admission => k8s.io/pod-security-admission v0.29.1
+
+replace k8s.io/sample-apiserver => k8s.io/sample-apiserver v0.29.1
@@ -7,8 +7,6 @@ SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" &>/dev/null && pwd)"
 PROJECT_DIR="$(cd "$(dirname "${SCRIPT_DIR}")" &>/dev/null && pwd)"
 LAUNCH_ENV_FILE="launch.env"
 LAUNCH_ENV_PATH="$PROJECT_DIR/$LAUNCH_ENV_FILE"
-K8S_SRC_TAR_URL="https://github.com/kubernetes/kubernetes/archive/refs/tags/v1.29.1.tar.gz"
-KUBE_SOURCE_DIR="$HOME/go/src/github.com/kubernetes/kubernetes"
 
 
 
@@ -63,7 +61,7 @@ main() {
   parse_flags "$@"
   validate_args
   validate_go_version
-  local GOOS GOARCH binaryAssetsDir kubeSchedulerBinaryUrl launchEnv kubeSchedulerGoMainFile landscapeFullName
+  local GOOS GOARCH binaryAssetsDir launchEnv landscapeFullName
 
   GOOS=$(go env GOOS)
   GOARCH=$(go env GOARCH)
@@ -76,58 +74,7 @@ main() {
   printf "Executing: %s\n" "$envTestSetupCmd"
   binaryAssetsDir=$(eval "$envTestSetupCmd")
 
-  if [[ ! -d "$KUBE_SOURCE_DIR" ]]; then
-    printf "No k8s sources at %s\n" "$KUBE_SOURCE_DIR"
-    pushd "/tmp" > /dev/null
-    printf "Downloading k8s source tarball from %s\n" "$K8S_SRC_TAR_URL"
-    curl -kL $K8S_SRC_TAR_URL -o /tmp/kubernetes.tar.gz
-    tar -zxf kubernetes.tar.gz
-    # TODO: make sure to create the directory under GOPATH if not present.
-    mv /tmp/kubernetes-1.29.1/ $KUBE_SOURCE_DIR
-    popd > /dev/null
-
-#    pushd "$KUBE_SOURCE_DIR" > /dev/null
-#    echo "Executing go mod tidy on $KUBE_SOURCE_DIR..."
-#    go mod tidy
-#    popd > /dev/null
-  fi
-
-
-  kubeSchedulerGoMainFile="$KUBE_SOURCE_DIR/cmd/kube-scheduler/scheduler.go"
-  if [[ ! -f  $kubeSchedulerGoMainFile ]]; then
-    echo -e "No kube-scheduler Go main file at: $kubeSchedulerGoMainFile"
-    exit 3
-  fi
-
-  if [[ ! -f "$binaryAssetsDir/kube-scheduler" ]]; then
-    echo -e "No kube-scheduler binary in: $binaryAssetsDir"
-    echo "Building kube-scheduler..."
-    pushd "$KUBE_SOURCE_DIR" > /dev/null
-    go build -v -o /tmp/kube-scheduler cmd/kube-scheduler/scheduler.go
-    chmod +w "$binaryAssetsDir"
-    cp -v /tmp/kube-scheduler "$binaryAssetsDir"
-    ls -al "$binaryAssetsDir/kube-scheduler"
-    popd > /dev/null
-  fi
 
-#  if [[ ! -f "$binaryAssetsDir/kube-controller-manager" ]]; then
-#    echo -e "No kube-controller-manager binary in: $binaryAssetsDir"
-#    echo "Building kube-controller-manager..."
-#    pushd "$KUBE_SOURCE_DIR" > /dev/null
-#    go build -v -o /tmp/kube-controller-manager cmd/kube-controller-manager/controller-manager.go
-#    chmod +w "$binaryAssetsDir"
-#    cp -v /tmp/kube-controller-manager "$binaryAssetsDir"
-#    popd > /dev/null
-#    ls -al "$binaryAssetsDir/kube-controller-manager"
-#  fi
-
-
-#  kubeSchedulerBinaryUrl="https://dl.k8s.io/v1.29.1/bin/$GOOS/$GOARCH/kube-scheduler"
-#  chmod u+w "$binaryAssetsDir"
-#  pushd "$binaryAssetsDir" > /dev/null
-#  printf "Downloading kube-scheduler binary from %s into %s\n" "$kubeSchedulerBinaryUrl" "$BINARY_ASSETS_DIR"
-#  curl -kLO "$kubeSchedulerBinaryUrl"
-#  chmod +x kube-scheduler
 
   landscapeFullName="sap-landscape-${LANDSCAPE_NAME}"
   loginCmd="gardenctl target --garden $landscapeFullName  --project $PROJECT"
@@ -138,8 +85,7 @@ main() {
   printf "BINARY_ASSETS_DIR=\"%s\"
 GARDEN_PROJECT_NAME=\"%s\"
 GARDEN_LANDSCAPE_NAME=\"%s\"
-GARDENCTL_KUBECONFIG=\"%s\"
-KUBE_SOURCE_DIR=\"%s\"" "$binaryAssetsDir" "$PROJECT" "$landscapeFullName" "$KUBECONFIG" "$KUBE_SOURCE_DIR"> "$LAUNCH_ENV_PATH"
+GARDENCTL_KUBECONFIG=\"%s\"" "$binaryAssetsDir" "$PROJECT" "$landscapeFullName" "$KUBECONFIG"> "$LAUNCH_ENV_PATH"
 
 
 
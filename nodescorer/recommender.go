@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/elankath/scaler-simulator/pricing"
+	"github.com/elankath/scaler-simulator/scorer"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
@@ -22,12 +23,19 @@ type Recommender struct {
 	scenarioName    string
 	shootName       string
 	strategyWeights scalesim.StrategyWeights
+	scorer          scorer.Scorer
 	logWriter       http.ResponseWriter
 	podOrder        string
 }
 
-func NewRecommender(engine scalesim.Engine, scenarioName, shootName, podOrder string, strategyWeights scalesim.StrategyWeights, logWriter http.ResponseWriter) *Recommender {
+// NewRecommender creates a Recommender. If nodeScorer is nil, runs are scored with the composite strategy using the
+// given strategy weights.
+func NewRecommender(engine scalesim.Engine, scenarioName, shootName, podOrder string, strategyWeights scalesim.StrategyWeights, nodeScorer scorer.Scorer, logWriter http.ResponseWriter) *Recommender {
+	if nodeScorer == nil {
+		nodeScorer = scorer.Composite{LeastWasteWeight: strategyWeights.LeastWaste, LeastCostWeight: strategyWeights.LeastCost}
+	}
 	return &Recommender{
+		scorer:          nodeScorer,
 		engine:          engine,
 		scenarioName:    scenarioName,
 		shootName:       shootName,
@@ -73,19 +81,12 @@ func (r *Recommender) Run(ctx context.Context, shoot *v1beta1.Shoot, unscheduled
 		recommendation.WorkerPoolName = winnerNodeScore.Pool.Name
 		recommendation.Replicas = recommendation.Replicas + 1
 		recommendation.Cost = pricing.GetPricing(winnerNodeScore.Pool.Machine.Type) * (float64(recommendation.Replicas))
-		checkEventsSince := time.Now()
 		scaledNode, err := r.scaleWorker(ctx, winnerNodeScore.Pool)
 		if err != nil {
 			r.logError(err)
 			return recommendations, err
 		}
-		if err = r.engine.VirtualClusterAccess().CreatePods(ctx, r.podOrder, unscheduledPods...); err != nil {
-			r.logError(err)
-			return recommendations, err
-		}
-
-		_, _, err = simutil.WaitForAndRecordPodSchedulingEvents(ctx, r.engine.VirtualClusterAccess(), r.logWriter, checkEventsSince, unscheduledPods, 10*time.Second)
-		if err != nil {
+		if _, err = r.engine.VirtualClusterAccess().SchedulePods(ctx, r.podOrder, unscheduledPods...); err != nil {
 			webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
 			return recommendations, err
 		}
@@ -137,14 +138,12 @@ func (r *Recommender) scaleWorker(ctx context.Context, worker *v1beta1.Worker) (
 
 func (r *Recommender) computeNodeScores(ctx context.Context, shoot *v1beta1.Shoot, candidatePods []corev1.Pod) scalesim.NodeRunResults {
 	nodeScores := scalesim.NodeRunResults(make(map[string]scalesim.NodeRunResult))
-	var checkEventsSince time.Time
 	for _, pool := range shoot.Spec.Provider.Workers {
 		//if err = r.engine.VirtualClusterAccess().DeletePods(ctx, candidatePods...); err != nil {
 		//	webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
 		//	return nil, candidatePods
 		//}
 		webutil.Logf(r.logWriter, "Scaling workerpool %s...", pool.Name)
-		checkEventsSince = time.Now()
 		scaledNode, err := simutil.CreateNodeInWorkerGroup(ctx, r.engine.VirtualClusterAccess(), &pool)
 		if err != nil {
 			webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
@@ -154,12 +153,7 @@ func (r *Recommender) computeNodeScores(ctx context.Context, shoot *v1beta1.Shoo
 			webutil.Log(r.logWriter, "No new node can be created for pool "+pool.Name+" as it has reached its max. Skipping this pool.")
 			continue
 		}
-		if err = r.engine.VirtualClusterAccess().CreatePods(ctx, r.podOrder, candidatePods...); err != nil {
-			webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
-			return nil
-		}
-		_, _, err = simutil.WaitForAndRecordPodSchedulingEvents(ctx, r.engine.VirtualClusterAccess(), r.logWriter, checkEventsSince, candidatePods, 10*time.Second)
-		if err != nil {
+		if _, err = r.engine.VirtualClusterAccess().SchedulePods(ctx, r.podOrder, candidatePods...); err != nil {
 			webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
 			return nil
 		}
@@ -169,7 +163,7 @@ func (r *Recommender) computeNodeScores(ctx context.Context, shoot *v1beta1.Shoo
 			return nil
 		}
 		candidatePods = simutil.GetMatchingPods(allPods, candidatePods)
-		nodeScore, err := computeNodeRunResult(r.strategyWeights, scaledNode, candidatePods, shoot.Spec.Provider.Workers)
+		nodeScore, err := computeNodeRunResult(r.strategyWeights, r.scorer, scaledNode, candidatePods, shoot.Spec.Provider.Workers)
 		if err != nil {
 			webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
 			return nil
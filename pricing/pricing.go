@@ -9,6 +9,9 @@ import (
 	"runtime"
 )
 
+// HoursPerMonth is the number of hours of a month that the monthly prices are based on.
+const HoursPerMonth = 730
+
 var pricingMap map[string]scalesim.InstancePricing
 
 func LoadInstancePricing() (map[string]scalesim.InstancePricing, error) {
@@ -34,6 +37,15 @@ func LoadInstancePricing() (map[string]scalesim.InstancePricing, error) {
 	return pricingMap, nil
 }
 
+// GetInstancePricing returns the pricing data of the instance type, which includes its cpu and memory.
+func GetInstancePricing(machineType string) (scalesim.InstancePricing, bool) {
+	if pricingMap == nil {
+		LoadInstancePricing()
+	}
+	instancePricing, ok := pricingMap[machineType]
+	return instancePricing, ok
+}
+
 func GetPricing(machineType string) float64 {
 	if pricingMap == nil {
 		LoadInstancePricing()
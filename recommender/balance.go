@@ -0,0 +1,154 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+// nodeGroup is a zone of a node pool, which is the unit that the cluster-autoscaler scales.
+type nodeGroup struct {
+	nodePool scalesim.NodePool
+	zone     string
+	// size is the number of nodes of the node pool in the zone.
+	size int32
+	// max is the maximum size of the node group.
+	max int32
+}
+
+// EnableBalancing makes the recommender distribute the increment of the winning run over all zones of the winning
+// node pool and of the node pools with the same machine type, like the cluster-autoscaler does with
+// --balance-similar-node-groups.
+func (r *Recommender) EnableBalancing() {
+	r.balancing = true
+}
+
+// similarNodeGroups returns the zones of the eligible node pools that are similar to the winning one: the zones of
+// the winning node pool itself and of the node pools with the same machine type and, in priority mode, the same
+// priority. The zone of the winning run comes first.
+func (r *Recommender) similarNodeGroups(winner runResult) []nodeGroup {
+	winningNodePool := r.state.eligibleNodePools[winner.nodePoolName]
+	nodePoolNames := make([]string, 0, len(r.state.eligibleNodePools))
+	for name, nodePool := range r.state.eligibleNodePools {
+		if nodePool.MachineType != winningNodePool.MachineType {
+			continue
+		}
+		if r.priorityMode && nodePool.Priority != winningNodePool.Priority {
+			continue
+		}
+		nodePoolNames = append(nodePoolNames, name)
+	}
+	slices.Sort(nodePoolNames)
+	var groups []nodeGroup
+	for _, name := range nodePoolNames {
+		nodePool := r.state.eligibleNodePools[name]
+		for _, zone := range nodePool.Zones {
+			group := nodeGroup{nodePool: nodePool, zone: zone, size: nodePool.ZoneSizes[zone], max: nodePool.ZoneLimits[zone].Max}
+			if name == winner.nodePoolName && zone == winner.zone {
+				groups = slices.Insert(groups, 0, group)
+			} else {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
+// balanceIncrement distributes the increment over the node groups by repeatedly adding a node to the smallest group
+// that has not reached its maximum. Ties are resolved in the order of the groups. It returns the increment of every
+// group.
+func balanceIncrement(groups []nodeGroup, increment int32) []int32 {
+	increments := make([]int32, len(groups))
+	for ; increment > 0; increment-- {
+		smallest := -1
+		for i, group := range groups {
+			if group.size+increments[i] >= group.max {
+				continue
+			}
+			if smallest < 0 || group.size+increments[i] < groups[smallest].size+increments[smallest] {
+				smallest = i
+			}
+		}
+		if smallest < 0 {
+			break
+		}
+		increments[smallest]++
+	}
+	return increments
+}
+
+// balanceWinner distributes the nodes of the winning run over the similar node groups and validates the split in a new
+// fork of the virtual cluster. The winning run is kept if the balanced one leaves more pods unscheduled.
+func (r *Recommender) balanceWinner(ctx context.Context, winner runResult) ([]Recommendation, runResult, error) {
+	unbalanced := []Recommendation{newRecommendation(winner)}
+	groups := r.similarNodeGroups(winner)
+	if len(groups) < 2 {
+		return unbalanced, winner, nil
+	}
+	increments := balanceIncrement(groups, int32(len(winner.nodeNames)))
+	var nodes []*corev1.Node
+	for i, group := range groups {
+		for j := int32(0); j < increments[i]; j++ {
+			node, err := r.constructNode(group.nodePool, group.zone)
+			if err != nil {
+				return nil, runResult{}, err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	fork, pods, usedNodes, err := r.simulateScaleUp(ctx, nodes)
+	if err != nil {
+		return nil, runResult{}, err
+	}
+	if len(usedNodes) == 0 {
+		webutil.Log(r.logWriter, fmt.Sprintf("Balanced scale-up over %d node groups leaves more pods unscheduled, keeping the unbalanced one", len(groups)))
+		fork.Shutdown()
+		return unbalanced, winner, nil
+	}
+	// the similar node groups share the machine type of the winner, so its node pool scores the balanced nodes.
+	score := r.computeNodeScore(r.state.eligibleNodePools[winner.nodePoolName], winner.zone, usedNodes, pods)
+	balanced := r.computeRunResult(winner.nodePoolName, winner.instanceType, winner.zone, usedNodes, score, pods)
+	if len(balanced.unscheduledPods) > len(winner.unscheduledPods) {
+		webutil.Log(r.logWriter, fmt.Sprintf("Balanced scale-up over %d node groups leaves more pods unscheduled, keeping the unbalanced one", len(groups)))
+		fork.Shutdown()
+		return unbalanced, winner, nil
+	}
+	winner.fork.Shutdown()
+	balanced.fork = fork
+
+	var recommendations []Recommendation
+	for _, group := range groups {
+		var incrementBy int32
+		for _, node := range usedNodes {
+			if node.Labels["worker.gardener.cloud/pool"] == group.nodePool.Name && node.Labels["topology.kubernetes.io/zone"] == group.zone {
+				incrementBy++
+			}
+		}
+		if incrementBy > 0 {
+			recommendations = append(recommendations, Recommendation{
+				zone:         group.zone,
+				nodePoolName: group.nodePool.Name,
+				incrementBy:  incrementBy,
+				instanceType: group.nodePool.MachineType,
+			})
+		}
+	}
+	balanced.nodeGroups = recommendations
+	webutil.Log(r.logWriter, fmt.Sprintf("Balanced scale-up of %d nodes: %s", len(usedNodes), RecommendationsString(recommendations)))
+	return recommendations, balanced, nil
+}
+
+// RecommendationsString joins the recommendations, e.g. "p1/eu-west-1a: +2, p1/eu-west-1b: +1".
+func RecommendationsString(recommendations []Recommendation) string {
+	parts := make([]string, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		parts = append(parts, recommendation.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,24 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+func TestBalanceIncrement(t *testing.T) {
+	p1 := scalesim.NodePool{Name: "p1", Max: 10, Current: 3}
+	p2 := scalesim.NodePool{Name: "p2", Max: 2, Current: 0}
+	groups := []nodeGroup{
+		{nodePool: p1, zone: "b", size: 2, max: 4},
+		{nodePool: p1, zone: "a", size: 1, max: 3},
+		{nodePool: p1, zone: "c", size: 0, max: 3},
+		{nodePool: p2, zone: "a", size: 0, max: 2},
+	}
+	assert.Equal(t, []int32{0, 1, 2, 1}, balanceIncrement(groups, 4))
+	assert.Equal(t, []int32{1, 2, 2, 2}, balanceIncrement(groups, 7))
+	// every node group is capped at its max.
+	assert.Equal(t, []int32{2, 2, 3, 2}, balanceIncrement(groups, 20))
+}
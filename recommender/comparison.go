@@ -0,0 +1,178 @@
+package recommender
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/pricing"
+	"github.com/elankath/scaler-simulator/resutil"
+	"github.com/elankath/scaler-simulator/simutil"
+)
+
+// ScaleUpEvaluation is the outcome of a scale-up for the unscheduled pods.
+type ScaleUpEvaluation struct {
+	NumNodes int32
+	// Cost is the monthly price of the scaled up nodes.
+	Cost float64
+	// WasteRatio is the weighted share of the resources of the scaled up nodes that stays unused, like the waste of the
+	// least-waste strategy.
+	WasteRatio float64
+	// UnscheduledPods is the number of pods that fit neither on the existing nor on the scaled up nodes.
+	UnscheduledPods int
+}
+
+// Comparison compares the scale-up recommended by the simulator with the one decided by the cluster-autoscaler for the
+// same unscheduled pods. The scale-up of the cluster-autoscaler is derived from the replicas of the machine
+// deployments, see AutoscalerScaleUps, so replicas added by someone else while the pods are unschedulable are
+// attributed to it.
+type Comparison struct {
+	Simulator  ScaleUpEvaluation
+	Autoscaler ScaleUpEvaluation
+	// Agreement tells whether both scale up the same node groups by the same number of nodes.
+	Agreement bool
+	// CostDelta is the cost of the scale-up of the simulator minus the one of the cluster-autoscaler.
+	CostDelta float64
+	// WasteDelta is the waste ratio of the scale-up of the simulator minus the one of the cluster-autoscaler.
+	WasteDelta float64
+}
+
+func (c Comparison) String() string {
+	agreement := "disagree"
+	if c.Agreement {
+		agreement = "agree"
+	}
+	return fmt.Sprintf("%s, cost delta: %+.2f/month, waste delta: %+.2f, unscheduled pods: %d (simulator) vs %d (cluster-autoscaler)",
+		agreement, c.CostDelta, c.WasteDelta, c.Simulator.UnscheduledPods, c.Autoscaler.UnscheduledPods)
+}
+
+// AutoscalerScaleUps returns the scale-ups that the cluster-autoscaler did between two states of the machine
+// deployments of the shoot, one per machine deployment whose replicas increased. Machine deployments that did not exist
+// before are skipped since they are created by Gardener, as are machine deployments that are rolled out since their
+// replicas change with the surge. Replicas up to the minimum of the zone are not counted either since Gardener
+// reconciles them. Other changes of the replicas, e.g. by hand, cannot be told apart from the cluster-autoscaler.
+func AutoscalerScaleUps(shoot *v1beta1.Shoot, before, after []*machinev1alpha1.MachineDeployment) []Recommendation {
+	previousMCDs := make(map[string]*machinev1alpha1.MachineDeployment, len(before))
+	for _, mcd := range before {
+		previousMCDs[mcd.Name] = mcd
+	}
+	var scaleUps []Recommendation
+	for _, mcd := range after {
+		previous, ok := previousMCDs[mcd.Name]
+		if !ok || mcd.Spec.Replicas <= previous.Spec.Replicas || isRollingOut(previous) || isRollingOut(mcd) {
+			continue
+		}
+		pool, zone, ok := simutil.MachineDeploymentNodeGroup(shoot, mcd)
+		if !ok {
+			continue
+		}
+		worker, _ := lo.Find(shoot.Spec.Provider.Workers, func(worker v1beta1.Worker) bool {
+			return worker.Name == pool
+		})
+		zoneMin := simutil.ComputeZoneLimits(&worker)[zone].Min
+		incrementBy := mcd.Spec.Replicas - max(previous.Spec.Replicas, zoneMin)
+		if incrementBy <= 0 {
+			continue
+		}
+		scaleUps = append(scaleUps, Recommendation{
+			zone:         zone,
+			nodePoolName: pool,
+			incrementBy:  incrementBy,
+			instanceType: worker.Machine.Type,
+		})
+	}
+	return scaleUps
+}
+
+// isRollingOut tells whether the machine deployment still has machines of a previous template.
+func isRollingOut(mcd *machinev1alpha1.MachineDeployment) bool {
+	return mcd.Status.UpdatedReplicas < mcd.Status.Replicas
+}
+
+// Compare evaluates the scale-ups of the simulator and of the cluster-autoscaler for the unscheduled pods against the
+// virtual cluster and compares them.
+func (r *Recommender) Compare(ctx context.Context, unscheduledPods []corev1.Pod, simulator, autoscaler []Recommendation) (Comparison, error) {
+	simulatorEvaluation, err := r.EvaluateScaleUp(ctx, unscheduledPods, simulator)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("cannot evaluate scale-up of simulator: %w", err)
+	}
+	autoscalerEvaluation, err := r.EvaluateScaleUp(ctx, unscheduledPods, autoscaler)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("cannot evaluate scale-up of cluster-autoscaler: %w", err)
+	}
+	return newComparison(simulator, autoscaler, simulatorEvaluation, autoscalerEvaluation), nil
+}
+
+func newComparison(simulator, autoscaler []Recommendation, simulatorEvaluation, autoscalerEvaluation ScaleUpEvaluation) Comparison {
+	return Comparison{
+		Simulator:  simulatorEvaluation,
+		Autoscaler: autoscalerEvaluation,
+		Agreement:  slices.Equal(mergeRecommendations(simulator), mergeRecommendations(autoscaler)),
+		CostDelta:  simulatorEvaluation.Cost - autoscalerEvaluation.Cost,
+		WasteDelta: simulatorEvaluation.WasteRatio - autoscalerEvaluation.WasteRatio,
+	}
+}
+
+// mergeRecommendations sums up the increments per node group and sorts them by node pool and zone.
+func mergeRecommendations(recommendations []Recommendation) []Recommendation {
+	var merged []Recommendation
+	for _, recommendation := range recommendations {
+		i := slices.IndexFunc(merged, func(m Recommendation) bool {
+			return m.nodePoolName == recommendation.nodePoolName && m.zone == recommendation.zone
+		})
+		if i < 0 {
+			merged = append(merged, recommendation)
+		} else {
+			merged[i].incrementBy += recommendation.incrementBy
+		}
+	}
+	slices.SortFunc(merged, func(a, b Recommendation) int {
+		return cmp.Or(cmp.Compare(a.nodePoolName, b.nodePoolName), cmp.Compare(a.zone, b.zone))
+	})
+	return merged
+}
+
+// EvaluateScaleUp adds the nodes of the scale-ups to a fork of the virtual cluster and schedules the unscheduled pods
+// onto the existing and the new nodes. Unlike Run it leaves the virtual cluster unchanged.
+func (r *Recommender) EvaluateScaleUp(ctx context.Context, unscheduledPods []corev1.Pod, scaleUps []Recommendation) (ScaleUpEvaluation, error) {
+	var evaluation ScaleUpEvaluation
+	if err := r.initializeSimulationState(ctx, r.shoot, unscheduledPods); err != nil {
+		return evaluation, err
+	}
+	var nodes []*corev1.Node
+	for _, scaleUp := range scaleUps {
+		nodePool := scalesim.NodePool{Name: scaleUp.nodePoolName, MachineType: scaleUp.instanceType}
+		for i := int32(0); i < scaleUp.incrementBy; i++ {
+			node, err := r.constructNode(nodePool, scaleUp.zone)
+			if err != nil {
+				return evaluation, err
+			}
+			nodes = append(nodes, node)
+		}
+		evaluation.NumNodes += scaleUp.incrementBy
+		evaluation.Cost += float64(scaleUp.incrementBy) * pricing.GetPricing(scaleUp.instanceType)
+	}
+	fork, pods, _, err := r.simulateScaleUp(ctx, nodes)
+	if err != nil {
+		return evaluation, err
+	}
+	fork.Shutdown()
+	evaluation.UnscheduledPods = countPodsOnNode(pods, "")
+	if len(nodes) > 0 {
+		// empty nodes are wasted as well, so the waste is computed over all nodes and not only the used ones. The scale-ups
+		// can mix instance types, so the resources of all nodes are taken into account.
+		capacities := lo.Map(nodes, func(node *corev1.Node, _ int) *corev1.Node {
+			return r.workloadCapacity(node)
+		})
+		wasteRatios := resutil.TotalWasteRatios(capacities, pods, resutil.NodeResources(capacities...))
+		evaluation.WasteRatio = resutil.WeightedWaste(wasteRatios, resutil.EffectiveWeights(r.strategyWeights.ResourceWeights, pods))
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,48 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAutoscalerScaleUps(t *testing.T) {
+	shoot := &v1beta1.Shoot{Spec: v1beta1.ShootSpec{Provider: v1beta1.Provider{Workers: []v1beta1.Worker{
+		{Name: "p1", Machine: v1beta1.Machine{Type: "m5.large"}, Zones: []string{"a", "b"}},
+	}}}}
+	newMCD := func(name string, replicas int32) *machinev1alpha1.MachineDeployment {
+		return &machinev1alpha1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       machinev1alpha1.MachineDeploymentSpec{Replicas: replicas},
+		}
+	}
+	before := []*machinev1alpha1.MachineDeployment{newMCD("shoot--dev--s-p1-z1", 1), newMCD("shoot--dev--s-p1-z2", 2)}
+	after := []*machinev1alpha1.MachineDeployment{newMCD("shoot--dev--s-p1-z1", 3), newMCD("shoot--dev--s-p1-z2", 1), newMCD("shoot--dev--s-p2-z1", 1)}
+	assert.Equal(t, []Recommendation{{nodePoolName: "p1", zone: "a", incrementBy: 2, instanceType: "m5.large"}}, AutoscalerScaleUps(shoot, before, after))
+
+	// replicas up to the zone minimum are reconciled by Gardener.
+	shoot.Spec.Provider.Workers[0].Minimum = 4
+	assert.Equal(t, []Recommendation{{nodePoolName: "p1", zone: "a", incrementBy: 1, instanceType: "m5.large"}}, AutoscalerScaleUps(shoot, before, after))
+
+	after[0].Status = machinev1alpha1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 2}
+	assert.Empty(t, AutoscalerScaleUps(shoot, before, after))
+}
+
+func TestNewComparison(t *testing.T) {
+	simulator := []Recommendation{{nodePoolName: "p1", zone: "b", incrementBy: 1}, {nodePoolName: "p1", zone: "a", incrementBy: 1}}
+	autoscaler := []Recommendation{{nodePoolName: "p1", zone: "a", incrementBy: 1}, {nodePoolName: "p1", zone: "b", incrementBy: 1}}
+	simulatorEvaluation := ScaleUpEvaluation{NumNodes: 2, Cost: 0.2, WasteRatio: 0.25}
+	autoscalerEvaluation := ScaleUpEvaluation{NumNodes: 2, Cost: 0.3, WasteRatio: 0.5, UnscheduledPods: 1}
+
+	comparison := newComparison(simulator, autoscaler, simulatorEvaluation, autoscalerEvaluation)
+	assert.True(t, comparison.Agreement)
+	assert.InDelta(t, -0.1, comparison.CostDelta, 1e-9)
+	assert.InDelta(t, -0.25, comparison.WasteDelta, 1e-9)
+
+	autoscaler = append(autoscaler, Recommendation{nodePoolName: "p1", zone: "a", incrementBy: 1})
+	assert.False(t, newComparison(simulator, autoscaler, simulatorEvaluation, autoscalerEvaluation).Agreement)
+	assert.False(t, newComparison(simulator, nil, simulatorEvaluation, ScaleUpEvaluation{}).Agreement)
+}
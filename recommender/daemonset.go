@@ -0,0 +1,126 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+
+	"github.com/elankath/scaler-simulator/resutil"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+// EnableDaemonSetPods makes the recommender place the DaemonSet pods of the shoot onto every node that it scales up, so
+// that only the capacity left by them is available to the pending pods. The requests of the DaemonSet pods are
+// multiplied with the given headroom factor of each resource to account for VPA scaling them up.
+func (r *Recommender) EnableDaemonSetPods(ctx context.Context, headroom map[corev1.ResourceName]float64) error {
+	dsPods, err := r.engine.ShootAccess(r.shoot.Name).GetDSPods(ctx)
+	if err != nil {
+		return err
+	}
+	r.dsPodTemplates = make([]corev1.Pod, 0, len(dsPods))
+	for _, pod := range dsPods {
+		r.dsPodTemplates = append(r.dsPodTemplates, newDaemonSetPodTemplate(pod, headroom))
+	}
+	webutil.Log(r.logWriter, fmt.Sprintf("Placing %d DaemonSet pods with headroom %v on new nodes", len(r.dsPodTemplates), headroom))
+	return nil
+}
+
+// newDaemonSetPodTemplate returns a copy of the DaemonSet pod that is not bound to any node and whose requests are
+// scaled by the headroom factors.
+func newDaemonSetPodTemplate(pod corev1.Pod, headroom map[corev1.ResourceName]float64) corev1.Pod {
+	template := corev1.Pod{
+		ObjectMeta: *pod.ObjectMeta.DeepCopy(),
+		Spec:       *pod.Spec.DeepCopy(),
+	}
+	template.ResourceVersion = ""
+	template.UID = ""
+	template.Spec.NodeName = ""
+	// the DaemonSet controller pins every pod to its node with a required node affinity on the node name.
+	if affinity := template.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		var terms []corev1.NodeSelectorTerm
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			var matchFields []corev1.NodeSelectorRequirement
+			for _, field := range term.MatchFields {
+				if field.Key != "metadata.name" {
+					matchFields = append(matchFields, field)
+				}
+			}
+			term.MatchFields = matchFields
+			if len(term.MatchExpressions) > 0 || len(term.MatchFields) > 0 {
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) == 0 {
+			affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nil
+		} else {
+			affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = terms
+		}
+	}
+	for _, containers := range [][]corev1.Container{template.Spec.InitContainers, template.Spec.Containers} {
+		for i := range containers {
+			scaleRequests(containers[i].Resources.Requests, headroom)
+		}
+	}
+	return template
+}
+
+func scaleRequests(requests corev1.ResourceList, factors map[corev1.ResourceName]float64) {
+	for name, quantity := range requests {
+		factor, ok := factors[name]
+		if !ok {
+			continue
+		}
+		requests[name] = *resource.NewMilliQuantity(int64(math.Ceil(float64(quantity.MilliValue())*factor)), quantity.Format)
+	}
+}
+
+// daemonSetPodsForNode returns the DaemonSet pods that run on the node bound to it, which are the ones whose node
+// selector and affinity match the node and that tolerate its taints.
+func (r *Recommender) daemonSetPodsForNode(node *corev1.Node) []corev1.Pod {
+	var pods []corev1.Pod
+	for _, template := range r.dsPodTemplates {
+		if match, err := nodeaffinity.GetRequiredNodeAffinity(&template).Match(node); err != nil || !match {
+			continue
+		}
+		_, untolerated := corev1helpers.FindMatchingUntoleratedTaint(node.Spec.Taints, template.Spec.Tolerations, func(taint *corev1.Taint) bool {
+			return taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute
+		})
+		if untolerated {
+			continue
+		}
+		pod := *template.DeepCopy()
+		pod.GenerateName = ""
+		pod.Name = template.GenerateName + node.Name
+		pod.Spec.NodeName = node.Name
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+// workloadCapacity returns a copy of the node whose allocatable resources are reduced by the requests of the DaemonSet
+// pods that run on it.
+func (r *Recommender) workloadCapacity(node *corev1.Node) *corev1.Node {
+	dsPods := r.daemonSetPodsForNode(node)
+	if len(dsPods) == 0 {
+		return node
+	}
+	capacity := node.DeepCopy()
+	for _, pod := range dsPods {
+		for name, request := range resutil.PodRequests(&pod) {
+			if quantity, ok := capacity.Status.Allocatable[name]; ok {
+				quantity.Sub(request)
+				capacity.Status.Allocatable[name] = quantity
+			}
+		}
+		if quantity, ok := capacity.Status.Allocatable[corev1.ResourcePods]; ok {
+			quantity.Sub(*resource.NewQuantity(1, resource.DecimalSI))
+			capacity.Status.Allocatable[corev1.ResourcePods] = quantity
+		}
+	}
+	return capacity
+}
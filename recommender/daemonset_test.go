@@ -0,0 +1,49 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetPodsForNode(t *testing.T) {
+	dsPod := newPod("100m", "1Gi")
+	dsPod.ObjectMeta = metav1.ObjectMeta{Name: "node-exporter-x2fz9", GenerateName: "node-exporter-", Namespace: "kube-system"}
+	dsPod.Spec.NodeName = "existing"
+	dsPod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"existing"}}}}},
+	}}}
+	gpuPod := newPod("100m", "100Mi")
+	gpuPod.ObjectMeta = metav1.ObjectMeta{GenerateName: "gpu-driver-", Namespace: "kube-system"}
+	gpuPod.Spec.NodeSelector = map[string]string{"gpu": "true"}
+
+	r := &Recommender{dsPodTemplates: []corev1.Pod{
+		newDaemonSetPodTemplate(dsPod, map[corev1.ResourceName]float64{corev1.ResourceMemory: 1.5}),
+		newDaemonSetPodTemplate(gpuPod, nil),
+	}}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "new", Labels: map[string]string{"gpu": "false"}},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+			corev1.ResourcePods:   resource.MustParse("10"),
+		}},
+	}
+	pods := r.daemonSetPodsForNode(node)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "node-exporter-new", pods[0].Name)
+	assert.Equal(t, "new", pods[0].Spec.NodeName)
+	assert.Nil(t, pods[0].Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	capacity := r.workloadCapacity(node)
+	assert.Equal(t, int64(1900), capacity.Status.Allocatable.Cpu().MilliValue())
+	assert.Equal(t, int64(6.5*1024*1024*1024), capacity.Status.Allocatable.Memory().Value())
+	assert.Equal(t, int64(9), capacity.Status.Allocatable.Pods().Value())
+	assert.Equal(t, resource.MustParse("8Gi"), node.Status.Allocatable[corev1.ResourceMemory])
+
+	node.Spec.Taints = []corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}}
+	assert.Empty(t, r.daemonSetPodsForNode(node))
+}
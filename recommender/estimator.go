@@ -0,0 +1,89 @@
+package recommender
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/elankath/scaler-simulator/resutil"
+)
+
+// estimateNodeCount estimates how many nodes like the given template node are needed to host the pods, packing them
+// first-fit in the order of descending size. Only resources are taken into account, the estimate is validated by
+// scheduling the pods onto that many nodes. Pods that do not fit on an empty node are ignored and the estimate is
+// capped at maxNodes.
+func estimateNodeCount(template *corev1.Node, pods []corev1.Pod, maxNodes int32) int32 {
+	resources := resutil.NodeResources(template)
+	allocatable := make([]int64, len(resources))
+	for i, res := range resources {
+		allocatable[i] = res.Allocatable(template)
+	}
+	var requests [][]int64
+	for i := range pods {
+		if len(resutil.InsufficientResources(template, &pods[i])) > 0 {
+			continue
+		}
+		podRequests := make([]int64, len(resources))
+		for j, res := range resources {
+			podRequests[j] = res.Request(&pods[i])
+		}
+		requests = append(requests, podRequests)
+	}
+	// the size of a pod is its largest share of any resource of the node.
+	size := func(podRequests []int64) float64 {
+		var maxShare float64
+		for i, request := range podRequests {
+			if allocatable[i] > 0 {
+				maxShare = max(maxShare, float64(request)/float64(allocatable[i]))
+			}
+		}
+		return maxShare
+	}
+	slices.SortStableFunc(requests, func(a, b []int64) int {
+		sizeA, sizeB := size(a), size(b)
+		switch {
+		case sizeA > sizeB:
+			return -1
+		case sizeA < sizeB:
+			return 1
+		}
+		return 0
+	})
+
+	var free [][]int64
+	for _, podRequests := range requests {
+		placed := false
+		for _, nodeFree := range free {
+			if fits(podRequests, nodeFree) {
+				subtract(nodeFree, podRequests)
+				placed = true
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+		if int32(len(free)) == maxNodes {
+			continue
+		}
+		nodeFree := slices.Clone(allocatable)
+		subtract(nodeFree, podRequests)
+		free = append(free, nodeFree)
+	}
+	return int32(len(free))
+}
+
+func fits(requests, free []int64) bool {
+	for i := range requests {
+		if requests[i] > free[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func subtract(free, requests []int64) {
+	for i := range requests {
+		free[i] -= requests[i]
+	}
+}
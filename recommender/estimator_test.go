@@ -0,0 +1,35 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newPod(cpu, memory string) corev1.Pod {
+	return corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}}}}}}
+}
+
+func TestEstimateNodeCount(t *testing.T) {
+	template := &corev1.Node{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}}}
+	var pods []corev1.Pod
+	for i := 0; i < 10; i++ {
+		pods = append(pods, newPod("1", "6Gi"))
+	}
+	assert.Equal(t, int32(5), estimateNodeCount(template, pods, 100))
+	assert.Equal(t, int32(3), estimateNodeCount(template, pods, 3))
+
+	// small pods fill the gaps left by the large ones, pods too large for the node are ignored.
+	pods = append(pods, newPod("1", "1Gi"), newPod("1", "1Gi"), newPod("8", "1Gi"))
+	assert.Equal(t, int32(5), estimateNodeCount(template, pods, 100))
+	assert.Equal(t, int32(0), estimateNodeCount(template, pods[12:], 100))
+}
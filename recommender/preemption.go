@@ -0,0 +1,199 @@
+package recommender
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/elankath/scaler-simulator/resutil"
+	"github.com/elankath/scaler-simulator/simutil"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+// DefaultExpendablePodsPriorityCutoff is the default of the cluster-autoscaler flag --expendable-pods-priority-cutoff.
+const DefaultExpendablePodsPriorityCutoff = -10
+
+// preemption describes a pending pod that is scheduled onto an existing node by preempting expendable pods from it
+// instead of triggering a scale-up.
+type preemption struct {
+	pod      types.NamespacedName
+	nodeName string
+	// victims are the expendable pods that are evicted from the node, it is empty if the pod fits without preemption.
+	victims []types.NamespacedName
+}
+
+func (p preemption) String() string {
+	if len(p.victims) == 0 {
+		return fmt.Sprintf("%s fits on node %s", p.pod, p.nodeName)
+	}
+	victims := make([]string, 0, len(p.victims))
+	for _, victim := range p.victims {
+		victims = append(victims, victim.String())
+	}
+	return fmt.Sprintf("%s preempts [%s] on node %s", p.pod, strings.Join(victims, ", "), p.nodeName)
+}
+
+// EnablePreemption makes the recommender treat pods with a priority below the cutoff like the cluster-autoscaler
+// treats expendable pods: pending expendable pods do not trigger a scale-up and before every run the pending pods that
+// fit onto the nodes of the virtual cluster once expendable pods are preempted are scheduled there instead. The
+// preempted pods are reported and removed from the virtual cluster.
+func (r *Recommender) EnablePreemption(expendablePodsPriorityCutoff int32) {
+	webutil.Log(r.logWriter, fmt.Sprintf("Preemption enabled with expendable pods priority cutoff %d", expendablePodsPriorityCutoff))
+	r.preemption = true
+	r.expendablePodsPriorityCutoff = expendablePodsPriorityCutoff
+}
+
+func (r *Recommender) isExpendable(pod *corev1.Pod) bool {
+	return corev1helpers.PodPriority(pod) < r.expendablePodsPriorityCutoff
+}
+
+// dropExpendablePods returns the pods that are not expendable.
+func (r *Recommender) dropExpendablePods(pods []corev1.Pod) []corev1.Pod {
+	var expendablePodNames []string
+	pods = slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		if r.isExpendable(&pod) {
+			expendablePodNames = append(expendablePodNames, pod.Name)
+			return true
+		}
+		return false
+	})
+	if len(expendablePodNames) > 0 {
+		webutil.Log(r.logWriter, fmt.Sprintf("Pending pods %v are below the expendable pods priority cutoff and do not trigger a scale-up", expendablePodNames))
+	}
+	return pods
+}
+
+// simulatePreemption schedules the unscheduled pods in a fork of the virtual cluster without its expendable pods.
+// Pods that are scheduled this way are bound in the virtual cluster and the expendable pods that no longer fit next to
+// them are deleted from it.
+func (r *Recommender) simulatePreemption(ctx context.Context) error {
+	vca := r.engine.VirtualClusterAccess()
+	pods, err := vca.ListPods(ctx)
+	if err != nil {
+		return err
+	}
+	if err = simutil.ResolvePodPriorities(pods, r.priorityClasses); err != nil {
+		return err
+	}
+	expendablePods := slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		return pod.Spec.NodeName == "" || !r.isExpendable(&pod)
+	})
+	candidatePods := slices.DeleteFunc(r.createUnscheduledPodsForSimRun(), func(pod corev1.Pod) bool {
+		return pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == corev1.PreemptNever
+	})
+	if len(expendablePods) == 0 || len(candidatePods) == 0 {
+		return nil
+	}
+
+	fork, err := vca.Fork(ctx)
+	if err != nil {
+		return err
+	}
+	defer fork.Shutdown()
+	if err = fork.DeletePods(ctx, expendablePods...); err != nil {
+		return err
+	}
+	schedulingResults, err := fork.SchedulePods(ctx, r.podOrder, candidatePods...)
+	if err != nil {
+		return err
+	}
+	scheduledPods := slices.DeleteFunc(simutil.ApplySchedulingResults(candidatePods, schedulingResults), func(pod corev1.Pod) bool {
+		return pod.Spec.NodeName == ""
+	})
+	if len(scheduledPods) == 0 {
+		return nil
+	}
+
+	var preemptions []preemption
+	var victims []corev1.Pod
+	for _, nodeName := range nodeNamesOf(scheduledPods) {
+		node, err := fork.GetNode(ctx, types.NamespacedName{Name: nodeName})
+		if err != nil {
+			return err
+		}
+		podsOnNode, err := simutil.GetPodsOnNode(ctx, fork, nodeName)
+		if err != nil {
+			return err
+		}
+		nodeVictims := preemptionVictims(node, podsOnNode, slices.DeleteFunc(slices.Clone(expendablePods), func(pod corev1.Pod) bool {
+			return pod.Spec.NodeName != nodeName
+		}))
+		victims = append(victims, nodeVictims...)
+		for _, pod := range scheduledPods {
+			if pod.Spec.NodeName != nodeName {
+				continue
+			}
+			p := preemption{pod: client.ObjectKeyFromObject(&pod), nodeName: nodeName}
+			for _, victim := range nodeVictims {
+				p.victims = append(p.victims, client.ObjectKeyFromObject(&victim))
+			}
+			preemptions = append(preemptions, p)
+		}
+	}
+
+	if err = vca.Merge(ctx, fork); err != nil {
+		return err
+	}
+	if err = vca.DeletePods(ctx, victims...); err != nil {
+		return err
+	}
+	for _, p := range preemptions {
+		webutil.Log(r.logWriter, fmt.Sprintf("No scale-up needed: %s", p))
+	}
+	r.state.preemptions = append(r.state.preemptions, preemptions...)
+	r.state.scheduledPods = append(r.state.scheduledPods, scheduledPods...)
+	r.state.unscheduledPods = slices.DeleteFunc(r.state.unscheduledPods, func(pod corev1.Pod) bool {
+		return slices.ContainsFunc(scheduledPods, func(scheduledPod corev1.Pod) bool {
+			return scheduledPod.Namespace == pod.Namespace && scheduledPod.Name == pod.Name
+		})
+	})
+	return nil
+}
+
+// preemptionVictims returns the expendable pods of the node that do not fit next to the pods on the node anymore.
+// Like the kube-scheduler, expendable pods of higher priority are kept first.
+func preemptionVictims(node *corev1.Node, podsOnNode []corev1.Pod, expendablePods []corev1.Pod) []corev1.Pod {
+	resources := resutil.NodeResources(node)
+	free := make([]int64, len(resources))
+	for i, res := range resources {
+		free[i] = res.Allocatable(node)
+		for j := range podsOnNode {
+			free[i] -= res.Request(&podsOnNode[j])
+		}
+	}
+	slices.SortStableFunc(expendablePods, func(a, b corev1.Pod) int {
+		return cmp.Compare(corev1helpers.PodPriority(&b), corev1helpers.PodPriority(&a))
+	})
+	var victims []corev1.Pod
+	for i := range expendablePods {
+		requests := make([]int64, len(resources))
+		for j, res := range resources {
+			requests[j] = res.Request(&expendablePods[i])
+		}
+		if fits(requests, free) {
+			subtract(free, requests)
+		} else {
+			victims = append(victims, expendablePods[i])
+		}
+	}
+	return victims
+}
+
+// nodeNamesOf returns the sorted names of the nodes that the pods are bound to.
+func nodeNamesOf(pods []corev1.Pod) []string {
+	var nodeNames []string
+	for _, pod := range pods {
+		if !slices.Contains(nodeNames, pod.Spec.NodeName) {
+			nodeNames = append(nodeNames, pod.Spec.NodeName)
+		}
+	}
+	slices.Sort(nodeNames)
+	return nodeNames
+}
@@ -0,0 +1,38 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	"github.com/elankath/scaler-simulator/simutil"
+)
+
+func TestPreemptionVictims(t *testing.T) {
+	node := &corev1.Node{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}}}
+	newExpendablePod := func(name string, priority int32, memory string) corev1.Pod {
+		pod := newPod("100m", memory)
+		pod.Name = name
+		pod.Spec.Priority = ptr.To(priority)
+		return pod
+	}
+	podsOnNode := []corev1.Pod{newPod("1", "8Gi"), newPod("1", "2Gi")}
+	expendablePods := []corev1.Pod{
+		newExpendablePod("low", -30, "4Gi"),
+		newExpendablePod("high", -20, "4Gi"),
+		newExpendablePod("small", -40, "1Gi"),
+	}
+	victims := preemptionVictims(node, podsOnNode, expendablePods)
+	assert.Equal(t, []string{"low"}, simutil.PodNames(victims))
+
+	podsOnNode = append(podsOnNode, newPod("1", "6Gi"))
+	victims = preemptionVictims(node, podsOnNode, expendablePods)
+	assert.Equal(t, []string{"high", "low", "small"}, simutil.PodNames(victims))
+}
@@ -0,0 +1,114 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/gardenclient"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+// PrioritiesAnnotation can be set on a shoot to override the priorities of the cluster-autoscaler priority expander
+// config map. Its value has the same format as the `priorities` key of the config map.
+const PrioritiesAnnotation = "scaler-simulator/priorities"
+
+const priorityExpanderConfigKey = "priorities"
+
+// EnablePriorityMode makes the recommender consider node pools of lower priority only if none of a higher priority
+// can host any pending pod. Priorities are read from the shoot annotation, then from the priority expander config
+// map of the shoot and default to the declaration order of the worker pools.
+func (r *Recommender) EnablePriorityMode(ctx context.Context) error {
+	configMap, err := r.engine.ShootAccess(r.shoot.Name).GetConfigMap(ctx, gardenclient.PriorityExpanderConfigMapNamespace, gardenclient.PriorityExpanderConfigMapName)
+	if err != nil {
+		return err
+	}
+	priorities, err := NodePoolPriorities(r.shoot, configMap)
+	if err != nil {
+		return err
+	}
+	webutil.Log(r.logWriter, fmt.Sprintf("Priority mode enabled with node pool priorities: %v", priorities))
+	r.priorityMode = true
+	r.nodePoolPriorities = priorities
+	return nil
+}
+
+// NodePoolPriorities returns the priority of every worker pool of the shoot configured in the cluster-autoscaler
+// priority expander format, which maps priorities to regular expressions matching node pool names. A pool matching
+// several expressions gets the highest priority, pools matching none rank below all others. Without any
+// configuration pools declared first have the higher priority.
+func NodePoolPriorities(shoot *v1beta1.Shoot, priorityExpanderConfigMap *corev1.ConfigMap) (map[string]int, error) {
+	config, ok := shoot.Annotations[PrioritiesAnnotation]
+	if !ok && priorityExpanderConfigMap != nil {
+		config, ok = priorityExpanderConfigMap.Data[priorityExpanderConfigKey]
+	}
+	workers := shoot.Spec.Provider.Workers
+	priorities := make(map[string]int, len(workers))
+	if !ok {
+		for i, worker := range workers {
+			priorities[worker.Name] = len(workers) - i
+		}
+		return priorities, nil
+	}
+	rules := make(map[int][]string)
+	if err := yaml.Unmarshal([]byte(config), &rules); err != nil {
+		return nil, fmt.Errorf("cannot parse node pool priorities of shoot %q: %w", shoot.Name, err)
+	}
+	levels := make([]int, 0, len(rules))
+	for level := range rules {
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no node pool priorities configured for shoot %q", shoot.Name)
+	}
+	slices.Sort(levels)
+	slices.Reverse(levels)
+	for _, worker := range workers {
+		priorities[worker.Name] = levels[len(levels)-1] - 1
+	}
+	assigned := make(map[string]bool, len(workers))
+	for _, level := range levels {
+		for _, expr := range rules[level] {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid node pool priority expression %q of shoot %q: %w", expr, shoot.Name, err)
+			}
+			for _, worker := range workers {
+				if !assigned[worker.Name] && re.MatchString(worker.Name) {
+					priorities[worker.Name] = level
+					assigned[worker.Name] = true
+				}
+			}
+		}
+	}
+	return priorities, nil
+}
+
+// nodePoolTiers groups the eligible node pools by descending priority. Without priority mode all pools form a
+// single tier.
+func (r *Recommender) nodePoolTiers() [][]scalesim.NodePool {
+	nodePools := make([]scalesim.NodePool, 0, len(r.state.eligibleNodePools))
+	for _, nodePool := range r.state.eligibleNodePools {
+		nodePools = append(nodePools, nodePool)
+	}
+	if !r.priorityMode {
+		return [][]scalesim.NodePool{nodePools}
+	}
+	slices.SortFunc(nodePools, func(a, b scalesim.NodePool) int {
+		return b.Priority - a.Priority
+	})
+	var tiers [][]scalesim.NodePool
+	for i, nodePool := range nodePools {
+		if i == 0 || nodePool.Priority != nodePools[i-1].Priority {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], nodePool)
+	}
+	return tiers
+}
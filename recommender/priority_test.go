@@ -0,0 +1,51 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+func TestNodePoolPriorities(t *testing.T) {
+	shoot := &v1beta1.Shoot{Spec: v1beta1.ShootSpec{Provider: v1beta1.Provider{Workers: []v1beta1.Worker{
+		{Name: "spot-small"}, {Name: "ondemand-large"}, {Name: "gpu"},
+	}}}}
+
+	priorities, err := NodePoolPriorities(shoot, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"spot-small": 3, "ondemand-large": 2, "gpu": 1}, priorities)
+
+	configMap := &corev1.ConfigMap{Data: map[string]string{"priorities": "10:\n  - ondemand-.*\n50:\n  - spot-.*\n  - .*small\n"}}
+	priorities, err = NodePoolPriorities(shoot, configMap)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"spot-small": 50, "ondemand-large": 10, "gpu": 9}, priorities)
+
+	shoot.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{PrioritiesAnnotation: "1:\n  - gpu\n"}}
+	priorities, err = NodePoolPriorities(shoot, configMap)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"spot-small": 0, "ondemand-large": 0, "gpu": 1}, priorities)
+
+	shoot.Annotations[PrioritiesAnnotation] = "1:\n  - '['\n"
+	_, err = NodePoolPriorities(shoot, nil)
+	assert.Error(t, err)
+}
+
+func TestNodePoolTiers(t *testing.T) {
+	r := &Recommender{state: simulationState{eligibleNodePools: map[string]scalesim.NodePool{
+		"a": {Name: "a", Priority: 10},
+		"b": {Name: "b", Priority: 50},
+		"c": {Name: "c", Priority: 10},
+	}}}
+	assert.Len(t, r.nodePoolTiers(), 1)
+
+	r.priorityMode = true
+	tiers := r.nodePoolTiers()
+	assert.Len(t, tiers, 2)
+	assert.Equal(t, []scalesim.NodePool{{Name: "b", Priority: 50}}, tiers[0])
+	assert.ElementsMatch(t, []scalesim.NodePool{{Name: "a", Priority: 10}, {Name: "c", Priority: 10}}, tiers[1])
+}
@@ -0,0 +1,128 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/elankath/scaler-simulator/pricing"
+	"github.com/elankath/scaler-simulator/resutil"
+)
+
+// wasteResources are the resources whose waste is reported in a Result.
+var wasteResources = []resutil.Resource{resutil.NewResource(corev1.ResourceCPU), resutil.NewResource(corev1.ResourceMemory)}
+
+// Result is the outcome of a run of the recommender. It is serialized to JSON for dashboards.
+type Result struct {
+	ShootName  string            `json:"shootName"`
+	Scenario   string            `json:"scenario"`
+	NodeGroups []NodeGroupResult `json:"nodeGroups"`
+	TotalNodes int32             `json:"totalNodes"`
+	// HourlyCost and MonthlyCost are the prices of all scaled up nodes.
+	HourlyCost  float64 `json:"hourlyCost"`
+	MonthlyCost float64 `json:"monthlyCost"`
+	// WasteRatios are the shares of cpu and memory of all scaled up nodes that stay unused.
+	WasteRatios     map[corev1.ResourceName]float64 `json:"wasteRatios"`
+	ScheduledPods   []PodResult                     `json:"scheduledPods"`
+	UnscheduledPods []PodResult                     `json:"unscheduledPods"`
+	StartTime       time.Time                       `json:"startTime"`
+	DurationSeconds float64                         `json:"durationSeconds"`
+	// Error is set if a run failed, the result then only covers the runs before.
+	Error string `json:"error,omitempty"`
+}
+
+// NodeGroupResult is the scale-up of a zone of a node pool.
+type NodeGroupResult struct {
+	NodePoolName string                          `json:"nodePool"`
+	Zone         string                          `json:"zone"`
+	InstanceType string                          `json:"instanceType"`
+	IncrementBy  int32                           `json:"incrementBy"`
+	HourlyCost   float64                         `json:"hourlyCost"`
+	MonthlyCost  float64                         `json:"monthlyCost"`
+	WasteRatios  map[corev1.ResourceName]float64 `json:"wasteRatios"`
+}
+
+// PodResult is a pod of the run, the node name is empty for pods that stay unscheduled.
+type PodResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	NodeName  string `json:"nodeName,omitempty"`
+}
+
+func (r *Result) String() string {
+	result := fmt.Sprintf("%d nodes (%s), cost: %.4f/h (%.2f/month), cpu waste: %.2f, memory waste: %.2f, scheduled pods: %d, unscheduled pods: %d, duration: %.2fs",
+		r.TotalNodes, nodeGroupsString(r.NodeGroups), r.HourlyCost, r.MonthlyCost, r.WasteRatios[corev1.ResourceCPU],
+		r.WasteRatios[corev1.ResourceMemory], len(r.ScheduledPods), len(r.UnscheduledPods), r.DurationSeconds)
+	if r.Error != "" {
+		result += ", error: " + r.Error
+	}
+	return result
+}
+
+func nodeGroupsString(nodeGroups []NodeGroupResult) string {
+	recommendations := make([]Recommendation, 0, len(nodeGroups))
+	for _, nodeGroup := range nodeGroups {
+		recommendations = append(recommendations, Recommendation{nodePoolName: nodeGroup.NodePoolName, zone: nodeGroup.Zone, incrementBy: nodeGroup.IncrementBy})
+	}
+	return RecommendationsString(recommendations)
+}
+
+// RunWithResult runs the recommender like Run and returns the result of the run. If a run fails, the partial result of
+// the preceding runs is returned together with the error.
+func (r *Recommender) RunWithResult(ctx context.Context, unscheduledPods []corev1.Pod) (*Result, error) {
+	startTime := time.Now()
+	recommendations, err := r.Run(ctx, unscheduledPods)
+	result := r.newResult(recommendations, startTime, time.Since(startTime))
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, err
+}
+
+// newResult breaks the recommendations down per node group with the nodes and pods of the winning runs. The waste of
+// the nodes is computed from the capacity left by the DaemonSet pods, like for the least-waste strategy.
+func (r *Recommender) newResult(recommendations []Recommendation, startTime time.Time, duration time.Duration) *Result {
+	result := &Result{
+		ShootName:       r.shoot.Name,
+		Scenario:        r.scenarioName,
+		NodeGroups:      []NodeGroupResult{},
+		ScheduledPods:   []PodResult{},
+		UnscheduledPods: []PodResult{},
+		StartTime:       startTime,
+		DurationSeconds: duration.Seconds(),
+	}
+	var allCapacities []*corev1.Node
+	for _, recommendation := range mergeRecommendations(recommendations) {
+		monthlyCost := float64(recommendation.incrementBy) * pricing.GetPricing(recommendation.instanceType)
+		var capacities []*corev1.Node
+		for i := range r.state.scaledUpNodes {
+			node := &r.state.scaledUpNodes[i]
+			if node.Labels["worker.gardener.cloud/pool"] == recommendation.nodePoolName && node.Labels["topology.kubernetes.io/zone"] == recommendation.zone {
+				capacities = append(capacities, r.workloadCapacity(node))
+			}
+		}
+		allCapacities = append(allCapacities, capacities...)
+		result.NodeGroups = append(result.NodeGroups, NodeGroupResult{
+			NodePoolName: recommendation.nodePoolName,
+			Zone:         recommendation.zone,
+			InstanceType: recommendation.instanceType,
+			IncrementBy:  recommendation.incrementBy,
+			HourlyCost:   monthlyCost / pricing.HoursPerMonth,
+			MonthlyCost:  monthlyCost,
+			WasteRatios:  resutil.TotalWasteRatios(capacities, r.state.scheduledPods, wasteResources),
+		})
+		result.TotalNodes += recommendation.incrementBy
+		result.MonthlyCost += monthlyCost
+	}
+	result.HourlyCost = result.MonthlyCost / pricing.HoursPerMonth
+	result.WasteRatios = resutil.TotalWasteRatios(allCapacities, r.state.scheduledPods, wasteResources)
+	for _, pod := range r.state.scheduledPods {
+		result.ScheduledPods = append(result.ScheduledPods, PodResult{Namespace: pod.Namespace, Name: pod.Name, NodeName: pod.Spec.NodeName})
+	}
+	for _, pod := range r.state.unscheduledPods {
+		result.UnscheduledPods = append(result.UnscheduledPods, PodResult{Namespace: pod.Namespace, Name: pod.Name})
+	}
+	return result
+}
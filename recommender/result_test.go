@@ -0,0 +1,75 @@
+package recommender
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elankath/scaler-simulator/pricing"
+)
+
+func TestNewResult(t *testing.T) {
+	newNode := func(name, zone string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+				"worker.gardener.cloud/pool":  "p1",
+				"topology.kubernetes.io/zone": zone,
+			}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			}},
+		}
+	}
+	newPod := func(name, nodeName string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			}}}}},
+		}
+	}
+	r := &Recommender{
+		shoot:        &v1beta1.Shoot{ObjectMeta: metav1.ObjectMeta{Name: "s"}},
+		scenarioName: "score5",
+		state: simulationState{
+			scaledUpNodes:   []corev1.Node{newNode("a1", "a"), newNode("b1", "b"), newNode("a2", "a")},
+			scheduledPods:   []corev1.Pod{newPod("p1", "a1"), newPod("p2", "a2"), newPod("p3", "a2"), newPod("p4", "b1"), newPod("p5", "existing")},
+			unscheduledPods: []corev1.Pod{newPod("p6", "")},
+		},
+	}
+	recommendations := []Recommendation{
+		{nodePoolName: "p1", zone: "a", incrementBy: 1, instanceType: "m5.large"},
+		{nodePoolName: "p1", zone: "b", incrementBy: 1, instanceType: "m5.large"},
+		{nodePoolName: "p1", zone: "a", incrementBy: 1, instanceType: "m5.large"},
+	}
+
+	result := r.newResult(recommendations, time.Now(), 2*time.Second)
+	price := pricing.GetPricing("m5.large")
+	assert.Equal(t, int32(3), result.TotalNodes)
+	assert.InDelta(t, 3*price, result.MonthlyCost, 1e-9)
+	assert.InDelta(t, 3*price/pricing.HoursPerMonth, result.HourlyCost, 1e-9)
+	assert.InDelta(t, 1/3.0, result.WasteRatios[corev1.ResourceCPU], 1e-9)
+	assert.InDelta(t, 2/3.0, result.WasteRatios[corev1.ResourceMemory], 1e-9)
+	assert.Len(t, result.NodeGroups, 2)
+	assert.Equal(t, int32(2), result.NodeGroups[0].IncrementBy)
+	assert.InDelta(t, 0.25, result.NodeGroups[0].WasteRatios[corev1.ResourceCPU], 1e-9)
+	assert.InDelta(t, 0.5, result.NodeGroups[1].WasteRatios[corev1.ResourceCPU], 1e-9)
+	assert.Len(t, result.ScheduledPods, 5)
+	assert.Equal(t, []PodResult{{Namespace: "default", Name: "p6"}}, result.UnscheduledPods)
+
+	data, err := json.Marshal(result)
+	assert.Nil(t, err)
+	var decoded map[string]any
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "b", decoded["nodeGroups"].([]any)[1].(map[string]any)["zone"])
+	assert.Equal(t, 2.0, decoded["durationSeconds"])
+	assert.NotContains(t, decoded, "error")
+}
@@ -11,85 +11,120 @@ import (
 	"github.com/elankath/scaler-simulator/simutil"
 	"github.com/elankath/scaler-simulator/webutil"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ScaleDownOrderedByDescendingCost scales down the nodes in the cluster ordered by descending cost. It does the following
-//  0. Order all existing nodes by their cost.
-//  1. Iterate over all existing ordered nodes, for each node:
-//     1.1 Taint the node with NoSchedule.
-//     1.2 Get the pods on the node and deploy a copy of the pods with new names in the cluster.
+//  0. Order all nodes by their cost. Nodes synced from the shoot are only considered with IncludeExistingNodes.
+//  1. Iterate over all ordered nodes, for each node:
+//     1.1 Check the utilization of the node against the threshold and the node and its pods against the scale-down
+//     rules, if any of them blocks the removal record the node as blocked with the reason.
+//     1.2 Delete the node, deploy a copy of the pods to move with new names in the cluster.
 //     1.3 Check whether there are un-scheduled pods.
 //     If len(unscheduledPods) == 0 {
-//     then delete the node from virtual cluster and record as recommendation
+//     then record the node as recommendation and its evicted pods against their PodDisruptionBudgets
 //     else {
-//     mark as essential (not to be removed).
+//     record the node as blocked since there is no place to move its pods.
 //     Delete the newly deployed pods.
-//     Un-taint the node if this node is essential.
+//     Re-create the node with its pods.
 //     }
-func ScaleDownOrderedByDescendingCost(ctx context.Context, vca scalesim.VirtualClusterAccess, w http.ResponseWriter, nodes []corev1.Node) ([]string, error) {
+//  2. Simulate after which time the cluster-autoscaler removes the recorded nodes.
+func ScaleDownOrderedByDescendingCost(ctx context.Context, vca scalesim.VirtualClusterAccess, w http.ResponseWriter, nodes []corev1.Node, opts ScaleDownOptions) ([]NodeRemoval, []BlockedNode, error) {
 	startTime := time.Now()
 	defer func() {
 		executionDuration := time.Since(startTime)
 		webutil.Log(w, fmt.Sprintf("ScaleDownOrderedByDescendingCost scale down recommender took %f seconds", executionDuration.Seconds()))
 	}()
 	slices.SortFunc(nodes, simutil.ComparePriceDescending)
-	var deletableNodeNames []string
+	var removals []NodeRemoval
+	var blockedNodes []BlockedNode
+
+	pdbs, err := vca.ListPodDisruptionBudgets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	allPods, err := vca.ListPods(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pdbTracker, err := newPDBTracker(pdbs, allPods)
+	if err != nil {
+		return nil, nil, err
+	}
+	block := func(blockedNode BlockedNode) {
+		webutil.Log(w, fmt.Sprintf("Node %s", blockedNode))
+		blockedNodes = append(blockedNodes, blockedNode)
+	}
 
 	for _, n := range nodes {
-		if simutil.IsExistingNode(&n) {
+		if simutil.IsExistingNode(&n) && !opts.IncludeExistingNodes {
+			continue
+		}
+		if n.Annotations[ScaleDownDisabledAnnotation] == "true" {
+			block(BlockedNode{Name: n.Name, Reason: ScaleDownDisabled})
 			continue
 		}
+		// the pods are fetched for every node since the pods of removed nodes have been moved to the remaining ones.
 		assignedPods, err := simutil.GetPodsOnNode(ctx, vca, n.Name)
 		if err != nil {
-			return deletableNodeNames, err
+			return removals, blockedNodes, err
+		}
+		utilization := nodeUtilization(&n, assignedPods, opts.IgnoreDaemonSetsUtilization)
+		if utilization >= opts.UtilizationThreshold {
+			webutil.Log(w, fmt.Sprintf("Node %s has utilization %.2f, not below threshold %.2f", n.Name, utilization, opts.UtilizationThreshold))
+			block(BlockedNode{Name: n.Name, Reason: NotUnderutilized})
+			continue
+		}
+		podsToMove, blockingPod, reason := opts.Rules.podsToMove(assignedPods, pdbTracker)
+		if blockingPod != nil {
+			block(BlockedNode{Name: n.Name, Reason: reason, Pod: client.ObjectKeyFromObject(blockingPod).String()})
+			continue
 		}
 
 		webutil.Log(w, "Deleting candidate node and corresponding pods: "+n.Name)
 		if err = simutil.DeleteNodeAndPods(ctx, w, vca, &n, assignedPods); err != nil {
-			return deletableNodeNames, err
+			return removals, blockedNodes, err
 		}
 
-		if len(assignedPods) == 0 {
-			deletableNodeNames = append(deletableNodeNames, n.Name)
-			webutil.Log(w, fmt.Sprintf("Node %s has no pods. Adding it to deletion candidates", n.Name))
+		if len(podsToMove) == 0 {
+			removals = append(removals, NodeRemoval{Name: n.Name, Utilization: utilization, Empty: true})
+			webutil.Log(w, fmt.Sprintf("Node %s has no pods to move. Adding it to deletion candidates", n.Name))
 			continue
 		}
 
-		adjustedPods := simutil.AdjustPods(assignedPods)
+		adjustedPods := simutil.AdjustPods(podsToMove)
 		adjustedPodNames := simutil.PodNames(adjustedPods)
 		webutil.Log(w, fmt.Sprintf("Deploying adjusted Pods...: %s", adjustedPodNames))
-		deployStartTime := time.Now()
-		if err = vca.CreatePods(ctx, "", adjustedPods...); err != nil {
-			return deletableNodeNames, err
-		}
-		scheduledPodNames, unscheduledPodNames, err := simutil.WaitForAndRecordPodSchedulingEvents(ctx, vca, w, deployStartTime, adjustedPods, 10*time.Second)
+		schedulingResults, err := vca.SchedulePods(ctx, "", adjustedPods...)
 		if err != nil {
-			return deletableNodeNames, err
-		} else {
-			webutil.Log(w, fmt.Sprintf("Scheduled pods: %v, unscheduled pods: %v", scheduledPodNames, unscheduledPodNames))
-			if len(unscheduledPodNames) != 0 {
-				webutil.Log(w, fmt.Sprintf("Node %s CANNOT be removed since it will result in %d unscheduled pods", n.Name, len(unscheduledPodNames)))
-				if err = vca.DeletePods(ctx, adjustedPods...); err != nil {
-					return deletableNodeNames, err
-				}
-				webutil.Log(w, fmt.Sprintf("Recreating node %s and corresponding pods %s", n.Name, simutil.PodNames(assignedPods)))
-				if err = recreateNodeWithPods(ctx, vca, &n, assignedPods); err != nil {
-					return deletableNodeNames, err
-				}
-			} else {
-				webutil.Log(w, fmt.Sprintf("Node %s can be removed, adding it to deletion candidates", n.Name))
-				deletableNodeNames = append(deletableNodeNames, n.Name)
+			return removals, blockedNodes, err
+		}
+		scheduledPodNames, unscheduledPodNames := simutil.SplitSchedulingResults(schedulingResults)
+		webutil.Log(w, fmt.Sprintf("Scheduled pods: %v, unscheduled pods: %v", scheduledPodNames, unscheduledPodNames))
+		if len(unscheduledPodNames) != 0 {
+			block(BlockedNode{Name: n.Name, Reason: NoPlaceToMovePods})
+			if err = vca.DeletePods(ctx, adjustedPods...); err != nil {
+				return removals, blockedNodes, err
 			}
+			webutil.Log(w, fmt.Sprintf("Recreating node %s and corresponding pods %s", n.Name, simutil.PodNames(assignedPods)))
+			if err = recreateNodeWithPods(ctx, vca, &n, assignedPods); err != nil {
+				return removals, blockedNodes, err
+			}
+		} else {
+			pdbTracker.evict(podsToMove)
+			webutil.Log(w, fmt.Sprintf("Node %s can be removed, adding it to deletion candidates", n.Name))
+			removals = append(removals, NodeRemoval{Name: n.Name, Utilization: utilization})
 		}
 	}
-	return deletableNodeNames, nil
+	opts.scheduleRemovals(removals)
+	return removals, blockedNodes, nil
 }
 
 func recreateNodeWithPods(ctx context.Context, vca scalesim.VirtualClusterAccess, node *corev1.Node, pods []corev1.Pod) error {
 	if err := vca.AddNodesAndUpdateLabels(ctx, node); err != nil {
 		return err
 	}
-	if err := vca.RemoveAllTaintsFromVirtualNode(ctx, node.Name); err != nil {
+	if err := vca.RemoveTaintFromVirtualNode(ctx, node.Name, corev1.TaintNodeNotReady); err != nil {
 		return err
 	}
 	for _, pod := range pods {
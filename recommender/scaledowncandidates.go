@@ -0,0 +1,99 @@
+package recommender
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/elankath/scaler-simulator/resutil"
+)
+
+// gpuResourceName is the resource that decides the utilization of GPU nodes, like for the cluster-autoscaler.
+const gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// NotUnderutilized is reported for nodes whose utilization is not below the scale-down utilization threshold.
+const NotUnderutilized BlockedReason = "NotUnderutilized"
+
+// ScaleDownOptions configures which nodes the scale-down recommender considers and when the cluster-autoscaler would
+// remove them. Apart from the rules and IncludeExistingNodes the fields mirror the cluster-autoscaler flags of the same
+// name.
+type ScaleDownOptions struct {
+	Rules ScaleDownRules
+	// IncludeExistingNodes makes the nodes synced from the shoot removal candidates. Their scheduled pods have to be
+	// synced as well.
+	IncludeExistingNodes bool
+	// UtilizationThreshold like --scale-down-utilization-threshold: only nodes whose utilization is below it are tried
+	// to be drained.
+	UtilizationThreshold float64
+	// IgnoreDaemonSetsUtilization like --ignore-daemonsets-utilization leaves DaemonSet pods out of the utilization.
+	IgnoreDaemonSetsUtilization bool
+	// UnneededTime like --scale-down-unneeded-time is how long a node has to be unneeded before it is removed.
+	UnneededTime time.Duration
+	// ScanInterval like --scan-interval is the time between two scale-down loops.
+	ScanInterval time.Duration
+	// MaxEmptyBulkDelete like --max-empty-bulk-delete is the number of empty nodes removed per loop.
+	MaxEmptyBulkDelete int
+	// MaxDrainParallelism like --max-drain-parallelism is the number of non-empty nodes drained per loop.
+	MaxDrainParallelism int
+}
+
+// DefaultScaleDownOptions returns the options that match the defaults of the cluster-autoscaler.
+func DefaultScaleDownOptions() ScaleDownOptions {
+	return ScaleDownOptions{
+		Rules:                DefaultScaleDownRules(),
+		UtilizationThreshold: 0.5,
+		UnneededTime:         10 * time.Minute,
+		ScanInterval:         10 * time.Second,
+		MaxEmptyBulkDelete:   10,
+		MaxDrainParallelism:  1,
+	}
+}
+
+// NodeRemoval is a node that the scale-down recommender removes.
+type NodeRemoval struct {
+	Name string
+	// Utilization of the node before the scale-down.
+	Utilization float64
+	// Empty tells whether the node had no pods that had to be moved.
+	Empty bool
+	// After is the simulated time after which the cluster-autoscaler removes the node.
+	After time.Duration
+}
+
+// nodeUtilization returns the utilization of the node like the cluster-autoscaler computes it: the highest share of
+// cpu and memory requested by the pods on the node, or the share of GPUs for nodes that offer them.
+func nodeUtilization(node *corev1.Node, podsOnNode []corev1.Pod, ignoreDaemonSets bool) float64 {
+	var pods []corev1.Pod
+	for i := range podsOnNode {
+		if !(ignoreDaemonSets && isDaemonSetPod(&podsOnNode[i])) {
+			pods = append(pods, podsOnNode[i])
+		}
+	}
+	resources := []resutil.Resource{resutil.NewResource(corev1.ResourceCPU), resutil.NewResource(corev1.ResourceMemory)}
+	if gpus := node.Status.Allocatable[gpuResourceName]; !gpus.IsZero() {
+		resources = []resutil.Resource{resutil.NewResource(gpuResourceName)}
+	}
+	var utilization float64
+	for _, wasteRatio := range resutil.WasteRatios(node, pods, resources) {
+		utilization = max(utilization, 1-wasteRatio)
+	}
+	return utilization
+}
+
+// scheduleRemovals sets the simulated time after which the nodes are removed. All of them are unneeded from the start,
+// so the first ones are removed once the unneeded time has passed. Every following loop removes further empty nodes
+// and drains further non-empty nodes up to their limits, in the order of the removals.
+func (o ScaleDownOptions) scheduleRemovals(removals []NodeRemoval) {
+	var numEmpty, numDrained int
+	for i := range removals {
+		var loop int
+		if removals[i].Empty {
+			loop = numEmpty / max(o.MaxEmptyBulkDelete, 1)
+			numEmpty++
+		} else {
+			loop = numDrained / max(o.MaxDrainParallelism, 1)
+			numDrained++
+		}
+		removals[i].After = o.UnneededTime + time.Duration(loop)*o.ScanInterval
+	}
+}
@@ -0,0 +1,50 @@
+package recommender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeUtilization(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	podWithRequests := func(name, cpu, memory string) corev1.Pod {
+		pod := replicatedPod("default", name, name)
+		pod.Spec.Containers = []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}}}
+		return pod
+	}
+	daemonSetPod := podWithRequests("ds", "1", "1Gi")
+	daemonSetPod.OwnerReferences[0].Kind = "DaemonSet"
+	pods := []corev1.Pod{podWithRequests("web", "1", "3Gi"), daemonSetPod}
+
+	assert.InDelta(t, 0.5, nodeUtilization(node, pods, false), 1e-9)
+	assert.InDelta(t, 0.375, nodeUtilization(node, pods, true), 1e-9)
+
+	node.Status.Allocatable[gpuResourceName] = resource.MustParse("2")
+	assert.InDelta(t, 0.0, nodeUtilization(node, pods, false), 1e-9)
+}
+
+func TestScheduleRemovals(t *testing.T) {
+	opts := DefaultScaleDownOptions()
+	opts.MaxEmptyBulkDelete = 2
+	removals := []NodeRemoval{{Name: "a", Empty: true}, {Name: "b"}, {Name: "c", Empty: true}, {Name: "d", Empty: true}, {Name: "e"}}
+	opts.scheduleRemovals(removals)
+	var after []time.Duration
+	for _, removal := range removals {
+		after = append(after, removal.After)
+	}
+	assert.Equal(t, []time.Duration{10 * time.Minute, 10 * time.Minute, 10 * time.Minute, 10*time.Minute + 10*time.Second, 10*time.Minute + 10*time.Second}, after)
+}
@@ -0,0 +1,252 @@
+package recommender
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Annotations that control the scale-down like they do for the cluster-autoscaler.
+const (
+	ScaleDownDisabledAnnotation       = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+	SafeToEvictAnnotation             = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	SafeToEvictLocalVolumesAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes"
+)
+
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// BlockedReason tells why a node cannot be scaled down. The names match the reasons reported by the cluster-autoscaler.
+type BlockedReason string
+
+const (
+	// ScaleDownDisabled is reported for nodes annotated with ScaleDownDisabledAnnotation.
+	ScaleDownDisabled BlockedReason = "ScaleDownDisabledAnnotation"
+	// NoPlaceToMovePods is reported if the pods of the node cannot be scheduled onto the remaining nodes.
+	NoPlaceToMovePods BlockedReason = "NoPlaceToMovePods"
+	// NotSafeToEvictAnnotation is reported for pods annotated with SafeToEvictAnnotation set to "false".
+	NotSafeToEvictAnnotation BlockedReason = "NotSafeToEvictAnnotation"
+	// NotReplicated is reported for pods that are not managed by a controller.
+	NotReplicated BlockedReason = "NotReplicated"
+	// LocalStorageRequested is reported for pods with hostPath or emptyDir volumes.
+	LocalStorageRequested BlockedReason = "LocalStorageRequested"
+	// UnmovableKubeSystemPod is reported for kube-system pods that are not covered by a PodDisruptionBudget.
+	UnmovableKubeSystemPod BlockedReason = "UnmovableKubeSystemPod"
+	// NotEnoughPdb is reported for pods whose PodDisruptionBudget does not allow another disruption.
+	NotEnoughPdb BlockedReason = "NotEnoughPdb"
+)
+
+// BlockedNode is a node that cannot be scaled down.
+type BlockedNode struct {
+	Name   string
+	Reason BlockedReason
+	// Pod is the namespaced name of the pod that blocks the scale-down, it is empty if the node itself is blocked.
+	Pod string
+}
+
+func (b BlockedNode) String() string {
+	if b.Pod == "" {
+		return fmt.Sprintf("%s blocked because %s", b.Name, b.Reason)
+	}
+	return fmt.Sprintf("%s blocked because %s of pod %s", b.Name, b.Reason, b.Pod)
+}
+
+// ScaleDownRules configures which pods block the scale-down of their node. Nodes annotated with
+// ScaleDownDisabledAnnotation and pods annotated with SafeToEvictAnnotation set to "false" always block it, while pods
+// annotated with SafeToEvictAnnotation set to "true" are only subject to their PodDisruptionBudgets.
+type ScaleDownRules struct {
+	// SkipNodesWithSystemPods blocks nodes with kube-system pods that are not covered by a PodDisruptionBudget, like the
+	// cluster-autoscaler flag --skip-nodes-with-system-pods.
+	SkipNodesWithSystemPods bool
+	// SkipNodesWithLocalStorage blocks nodes with pods using hostPath or emptyDir volumes, like the cluster-autoscaler
+	// flag --skip-nodes-with-local-storage.
+	SkipNodesWithLocalStorage bool
+	// SkipNodesWithUnreplicatedPods blocks nodes with pods that are not managed by a controller.
+	SkipNodesWithUnreplicatedPods bool
+	// RespectPodDisruptionBudgets blocks nodes whose pods cannot be evicted without violating a PodDisruptionBudget.
+	RespectPodDisruptionBudgets bool
+}
+
+// DefaultScaleDownRules returns the rules that match the defaults of the cluster-autoscaler.
+func DefaultScaleDownRules() ScaleDownRules {
+	return ScaleDownRules{
+		SkipNodesWithSystemPods:       true,
+		SkipNodesWithLocalStorage:     true,
+		SkipNodesWithUnreplicatedPods: true,
+		RespectPodDisruptionBudgets:   true,
+	}
+}
+
+// podsToMove returns the pods that have to be re-scheduled if the node is removed. DaemonSet, mirror and terminated
+// pods are not moved. If a pod blocks the removal, it is returned with the reason instead.
+func (r ScaleDownRules) podsToMove(podsOnNode []corev1.Pod, pdbs *pdbTracker) (podsToMove []corev1.Pod, blockingPod *corev1.Pod, reason BlockedReason) {
+	for i := range podsOnNode {
+		pod := &podsOnNode[i]
+		if isMirrorPod(pod) || isDaemonSetPod(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if reason = r.blockedReason(pod, pdbs); reason != "" {
+			return nil, pod, reason
+		}
+		podsToMove = append(podsToMove, *pod)
+	}
+	if r.RespectPodDisruptionBudgets {
+		if pod := pdbs.firstBlocked(podsToMove); pod != nil {
+			return nil, pod, NotEnoughPdb
+		}
+	}
+	return podsToMove, nil, ""
+}
+
+func (r ScaleDownRules) blockedReason(pod *corev1.Pod, pdbs *pdbTracker) BlockedReason {
+	switch pod.Annotations[SafeToEvictAnnotation] {
+	case "true":
+		return ""
+	case "false":
+		return NotSafeToEvictAnnotation
+	}
+	if r.SkipNodesWithUnreplicatedPods && metav1.GetControllerOf(pod) == nil {
+		return NotReplicated
+	}
+	if r.SkipNodesWithLocalStorage && hasLocalStorage(pod) {
+		return LocalStorageRequested
+	}
+	if r.SkipNodesWithSystemPods && pod.Namespace == metav1.NamespaceSystem && !pdbs.covers(pod) {
+		return UnmovableKubeSystemPod
+	}
+	return ""
+}
+
+func isMirrorPod(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[mirrorPodAnnotation]
+	return ok
+}
+
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	controller := metav1.GetControllerOf(pod)
+	return controller != nil && controller.Kind == "DaemonSet"
+}
+
+// hasLocalStorage tells whether the pod uses hostPath or non-memory emptyDir volumes that are not listed in the
+// SafeToEvictLocalVolumesAnnotation.
+func hasLocalStorage(pod *corev1.Pod) bool {
+	safeVolumes := strings.Split(pod.Annotations[SafeToEvictLocalVolumesAnnotation], ",")
+	for _, volume := range pod.Spec.Volumes {
+		if slices.Contains(safeVolumes, volume.Name) {
+			continue
+		}
+		if volume.HostPath != nil || (volume.EmptyDir != nil && volume.EmptyDir.Medium != corev1.StorageMediumMemory) {
+			return true
+		}
+	}
+	return false
+}
+
+// pdbTracker tracks how many more disruptions each PodDisruptionBudget allows while nodes are removed.
+type pdbTracker struct {
+	pdbs      []policyv1.PodDisruptionBudget
+	selectors []labels.Selector
+	remaining []int32
+}
+
+// newPDBTracker returns a tracker of the PodDisruptionBudgets. Budgets with a status, like those synced from a shoot,
+// allow the disruptions of their status, otherwise the allowed disruptions are computed from the spec and the given
+// pods like the disruption controller does.
+func newPDBTracker(pdbs []policyv1.PodDisruptionBudget, pods []corev1.Pod) (*pdbTracker, error) {
+	t := &pdbTracker{pdbs: pdbs, selectors: make([]labels.Selector, len(pdbs)), remaining: make([]int32, len(pdbs))}
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		selector := labels.Nothing()
+		if pdb.Spec.Selector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(pdb.Spec.Selector); err != nil {
+				return nil, fmt.Errorf("invalid selector of pod disruption budget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+			}
+		}
+		t.selectors[i] = selector
+		if pdb.Status.ObservedGeneration > 0 || pdb.Status.ExpectedPods > 0 {
+			t.remaining[i] = pdb.Status.DisruptionsAllowed
+			continue
+		}
+		var expected, healthy int
+		for j := range pods {
+			if t.matches(i, &pods[j]) {
+				expected++
+				if pods[j].Spec.NodeName != "" {
+					healthy++
+				}
+			}
+		}
+		allowed, err := disruptionsAllowed(pdb, expected, healthy)
+		if err != nil {
+			return nil, err
+		}
+		t.remaining[i] = int32(allowed)
+	}
+	return t, nil
+}
+
+func disruptionsAllowed(pdb *policyv1.PodDisruptionBudget, expected, healthy int) (int, error) {
+	desiredHealthy := 0
+	if pdb.Spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, expected, true)
+		if err != nil {
+			return 0, fmt.Errorf("invalid maxUnavailable of pod disruption budget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+		}
+		desiredHealthy = expected - maxUnavailable
+	} else if pdb.Spec.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, expected, true)
+		if err != nil {
+			return 0, fmt.Errorf("invalid minAvailable of pod disruption budget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+		}
+		desiredHealthy = minAvailable
+	}
+	return max(healthy-desiredHealthy, 0), nil
+}
+
+func (t *pdbTracker) matches(i int, pod *corev1.Pod) bool {
+	return t.pdbs[i].Namespace == pod.Namespace && t.selectors[i].Matches(labels.Set(pod.Labels))
+}
+
+// covers tells whether any PodDisruptionBudget matches the pod.
+func (t *pdbTracker) covers(pod *corev1.Pod) bool {
+	for i := range t.pdbs {
+		if t.matches(i, pod) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstBlocked returns the first pod whose eviction together with the previous pods exceeds a budget.
+func (t *pdbTracker) firstBlocked(pods []corev1.Pod) *corev1.Pod {
+	remaining := slices.Clone(t.remaining)
+	for j := range pods {
+		for i := range t.pdbs {
+			if !t.matches(i, &pods[j]) {
+				continue
+			}
+			if remaining[i] <= 0 {
+				return &pods[j]
+			}
+			remaining[i]--
+		}
+	}
+	return nil
+}
+
+// evict records the eviction of the pods against their budgets.
+func (t *pdbTracker) evict(pods []corev1.Pod) {
+	for j := range pods {
+		for i := range t.pdbs {
+			if t.matches(i, &pods[j]) {
+				t.remaining[i]--
+			}
+		}
+	}
+}
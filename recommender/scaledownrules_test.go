@@ -0,0 +1,75 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func replicatedPod(namespace, name, app string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			Labels:          map[string]string{"app": app},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: app, Controller: ptr.To(true)}},
+		},
+		Spec: corev1.PodSpec{NodeName: "n1"},
+	}
+}
+
+func TestScaleDownRulesPodsToMove(t *testing.T) {
+	minAvailable := intstr.FromInt32(1)
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}}
+	web1, web2 := replicatedPod("default", "web-1", "web"), replicatedPod("default", "web-2", "web")
+	tracker, err := newPDBTracker(pdbs, []corev1.Pod{web1, web2})
+	assert.NoError(t, err)
+
+	daemonSetPod := replicatedPod("default", "ds", "ds")
+	daemonSetPod.OwnerReferences[0].Kind = "DaemonSet"
+	standalonePod := replicatedPod("default", "standalone", "standalone")
+	standalonePod.OwnerReferences = nil
+	safePod := standalonePod
+	safePod.Annotations = map[string]string{SafeToEvictAnnotation: "true"}
+	notSafePod := replicatedPod("default", "not-safe", "not-safe")
+	notSafePod.Annotations = map[string]string{SafeToEvictAnnotation: "false"}
+	localStoragePod := replicatedPod("default", "cache", "cache")
+	localStoragePod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	systemPod := replicatedPod(metav1.NamespaceSystem, "dns", "dns")
+
+	rules := DefaultScaleDownRules()
+	podsToMove, blockingPod, reason := rules.podsToMove([]corev1.Pod{daemonSetPod, safePod, web1}, tracker)
+	assert.Nil(t, blockingPod)
+	assert.Empty(t, reason)
+	assert.Equal(t, []string{"standalone", "web-1"}, []string{podsToMove[0].Name, podsToMove[1].Name})
+	tracker.evict(podsToMove)
+
+	for _, tc := range []struct {
+		pod    corev1.Pod
+		reason BlockedReason
+	}{
+		{standalonePod, NotReplicated},
+		{notSafePod, NotSafeToEvictAnnotation},
+		{localStoragePod, LocalStorageRequested},
+		{systemPod, UnmovableKubeSystemPod},
+		{web2, NotEnoughPdb},
+	} {
+		_, blockingPod, reason = rules.podsToMove([]corev1.Pod{tc.pod}, tracker)
+		assert.Equal(t, tc.reason, reason, tc.pod.Name)
+		assert.Equal(t, tc.pod.Name, blockingPod.Name)
+	}
+
+	localStoragePod.Annotations = map[string]string{SafeToEvictLocalVolumesAnnotation: "data"}
+	rules.RespectPodDisruptionBudgets = false
+	podsToMove, blockingPod, _ = rules.podsToMove([]corev1.Pod{localStoragePod, web2}, tracker)
+	assert.Nil(t, blockingPod)
+	assert.Len(t, podsToMove, 2)
+}
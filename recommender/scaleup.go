@@ -1,58 +1,60 @@
 package recommender
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"math"
 	"net/http"
 	"slices"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/elankath/scaler-simulator/pricing"
+	"github.com/elankath/scaler-simulator/resutil"
+	"github.com/elankath/scaler-simulator/scorer"
 	"github.com/samber/lo"
-	"golang.org/x/exp/maps"
 	"golang.org/x/exp/rand"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/elankath/scaler-simulator/simutil"
 	"github.com/elankath/scaler-simulator/virtualcluster"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	scalesim "github.com/elankath/scaler-simulator"
 	"github.com/elankath/scaler-simulator/webutil"
 	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 )
 
 /*
 		for {
 			unscheduledPods = determine unscheduled pods
 			if noUnscheduledPods then exit early
+			- in preemption mode, schedule the pods that fit onto the virtual cluster once expendable pods are preempted.
 			- runSimulation
 	 		  - Start a go-routine for each of candidate nodePool which are eligible
-					- eligibility: max is not yet reached for that nodePool
-	              For each go-routine:
-	                Setup:
-	                    - create a unique label that will get added to all nodes and pods
-	                	- copy previous winner nodes and add a taint.
-	                	- copy the deployed pods with node names assigned and add toleration to the taint.
-		            - scale up one node, add a taint and only copy of pods will have toleration to that taint.
-	                - copy of unscheduled pods, add a toleration for this taint.
-	                - wait for pods to be scheduled
-	                - compute node score.
+					- eligibility: the max of the machine deployment is not yet reached in a zone of that nodePool
+	              For each go-routine and each eligible zone of the nodePool:
+	                - estimate the number of nodes needed for the unscheduled pods by binpacking.
+	                - fork the virtual cluster.
+		            - scale up the estimated number of nodes in the fork.
+	                - schedule a copy of the unscheduled pods in the fork.
+	                - remove the new nodes that stayed empty and compute the score of the others.
+			- in balancing mode, distribute the nodes of the winning run over similar node groups.
+			- merge the fork of the winning run back into the virtual cluster.
 		}
 */
-const resourceNameFormat = "%s-simrun-%s"
 
 type StrategyWeights struct {
 	LeastWaste float64
 	LeastCost  float64
+	// ResourceWeights weights the waste of individual resources, see resutil.EffectiveWeights for the defaults.
+	ResourceWeights map[corev1.ResourceName]float64
 }
 
 type Recommendation struct {
@@ -62,58 +64,71 @@ type Recommendation struct {
 	instanceType string
 }
 
+func (r Recommendation) String() string {
+	return fmt.Sprintf("%s/%s: +%d", r.nodePoolName, r.zone, r.incrementBy)
+}
+
 type Recommender struct {
 	engine                 scalesim.Engine
 	scenarioName           string
 	shoot                  *v1beta1.Shoot
 	strategyWeights        StrategyWeights
+	scorer                 scorer.Scorer
 	logWriter              http.ResponseWriter
 	state                  simulationState
 	podOrder               string
 	instanceTypeCostRatios map[string]float64
+	// priorityMode restricts each run to the node pools of the highest priority that can host a pending pod.
+	priorityMode       bool
+	nodePoolPriorities map[string]int
+	// balancing distributes the increment of each run over similar node groups.
+	balancing bool
+	// dsPodTemplates are the DaemonSet pods placed on every scaled up node.
+	dsPodTemplates []corev1.Pod
+	// preemption lets pending pods preempt pods below the expendable pods priority cutoff instead of scaling up.
+	preemption                   bool
+	expendablePodsPriorityCutoff int32
+	priorityClasses              []schedulingv1.PriorityClass
 }
 
 type nodeScore struct {
-	memoryWasteRatio float64
-	cpuWasteRatio    float64
+	wasteRatios      map[corev1.ResourceName]float64
+	wasteRatio       float64
 	unscheduledRatio float64
 	costRatio        float64
 	cumulativeScore  float64
 }
 
 type runResult struct {
-	nodePoolName    string
-	nodeName        string
-	zone            string
+	nodePoolName string
+	// nodeNames are the names of the nodes scaled up by the run.
+	nodeNames []string
+	zone      string
+	// nodeGroups are the node pools and zones that the nodes of a balanced run are spread over, the nodes of other runs
+	// all belong to nodePoolName and zone.
+	nodeGroups      []Recommendation
 	instanceType    string
 	nodeScore       nodeScore
 	unscheduledPods []corev1.Pod
 	nodeToPods      map[string][]types.NamespacedName
-	err             error
+	// fork is the virtual cluster that the run was simulated in.
+	fork scalesim.VirtualClusterAccess
+	err  error
 }
 
 func (r runResult) HasWinner() bool {
 	return len(r.nodeToPods) > 0
 }
 
-type simRunRef struct {
-	key   string
-	value string
-}
-
-func (s simRunRef) asMap() map[string]string {
-	return map[string]string{
-		s.key: s.value,
-	}
-}
-
 type simulationState struct {
-	originalPods    map[string]corev1.Pod
-	existingNodes   []corev1.Node
+	// scaledUpNodes are the nodes added by the winning runs.
+	scaledUpNodes   []corev1.Node
 	unscheduledPods []corev1.Pod
 	scheduledPods   []corev1.Pod
 	// eligibleNodePools holds the available node capacity per node pool.
 	eligibleNodePools map[string]scalesim.NodePool
+	// preemptions are the pending pods scheduled by preempting expendable pods.
+	preemptions []preemption
 }
 
 func (s *simulationState) updateEligibleNodePools(recommendation *Recommendation) {
@@ -122,25 +137,45 @@ func (s *simulationState) updateEligibleNodePools(recommendation *Recommendation
 		return
 	}
 	np.Current += recommendation.incrementBy
-	if np.Current == np.Max {
+	np.ZoneSizes[recommendation.zone] += recommendation.incrementBy
+	if np.ZoneSizes[recommendation.zone] >= np.ZoneLimits[recommendation.zone].Max {
+		np.Zones = slices.DeleteFunc(np.Zones, func(zone string) bool {
+			return zone == recommendation.zone
+		})
+	}
+	if len(np.Zones) == 0 {
 		delete(s.eligibleNodePools, recommendation.nodePoolName)
 	} else {
 		s.eligibleNodePools[recommendation.nodePoolName] = np
 	}
 }
 
-func NewRecommender(engine scalesim.Engine, scenarioName, podOrder string, shoot *v1beta1.Shoot, instanceTypeCostRatios map[string]float64, strategyWeights StrategyWeights, logWriter http.ResponseWriter) *Recommender {
-	return &Recommender{
+// NewRecommender creates a scale-up Recommender. If nodeScorer is nil, runs are scored with the composite strategy
+// using the given strategy weights. If instanceTypeCostRatios is nil, the ratios are computed from the prices of the
+// instance types of the worker pools.
+func NewRecommender(engine scalesim.Engine, scenarioName, podOrder string, shoot *v1beta1.Shoot, instanceTypeCostRatios map[string]float64, strategyWeights StrategyWeights, nodeScorer scorer.Scorer, logWriter http.ResponseWriter) *Recommender {
+	if nodeScorer == nil {
+		nodeScorer = scorer.Composite{LeastWasteWeight: strategyWeights.LeastWaste, LeastCostWeight: strategyWeights.LeastCost}
+	}
+	r := &Recommender{
 		engine:                 engine,
 		scenarioName:           scenarioName,
 		shoot:                  shoot,
 		strategyWeights:        strategyWeights,
+		scorer:                 nodeScorer,
 		logWriter:              logWriter,
 		instanceTypeCostRatios: instanceTypeCostRatios,
 		podOrder:               podOrder,
 	}
+	if instanceTypeCostRatios == nil {
+		r.instanceTypeCostRatios = make(map[string]float64, len(shoot.Spec.Provider.Workers))
+		r.computeCostRatiosForInstanceTypes(shoot.Spec.Provider.Workers)
+	}
+	return r
 }
 
+// Run recommends the scale-ups for the unscheduled pods run by run until all pods are scheduled or no node pool can
+// host any of them. If a run fails, the recommendations of the preceding runs are returned together with the error.
 func (r *Recommender) Run(ctx context.Context, unscheduledPods []corev1.Pod) ([]Recommendation, error) {
 	var (
 		recommendations []Recommendation
@@ -152,6 +187,11 @@ func (r *Recommender) Run(ctx context.Context, unscheduledPods []corev1.Pod) ([]
 		return recommendations, err
 	}
 
+	if err := r.scheduleOnExistingNodes(ctx); err != nil {
+		webutil.InternalError(r.logWriter, err)
+		return recommendations, err
+	}
+
 	for {
 		runNumber++
 		webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d started...", runNumber))
@@ -159,29 +199,40 @@ func (r *Recommender) Run(ctx context.Context, unscheduledPods []corev1.Pod) ([]
 			webutil.Log(r.logWriter, "All pods are scheduled. Exiting the loop...")
 			break
 		}
+		if r.preemption {
+			if err := r.simulatePreemption(ctx); err != nil {
+				webutil.InternalError(r.logWriter, err)
+				return recommendations, err
+			}
+			if len(r.state.unscheduledPods) == 0 {
+				webutil.Log(r.logWriter, "All pods are scheduled by preemption. Exiting the loop...")
+				break
+			}
+		}
 		simRunStartTime := time.Now()
-		recommendation, winnerRunResult, err := r.runSimulation(ctx, runNumber)
+		runRecommendations, winnerRunResult, err := r.runSimulation(ctx, runNumber)
 		webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d completed in %f seconds", runNumber, time.Since(simRunStartTime).Seconds()))
 		if err != nil {
-			webutil.Log(r.logWriter, fmt.Sprintf("runSimulation for shoot %s failed, err: %v", r.shoot.Name, err))
-			break
+			webutil.InternalError(r.logWriter, err)
+			return recommendations, fmt.Errorf("runSimulation #%d for shoot %s failed: %w", runNumber, r.shoot.Name, err)
 		}
 
-		if recommendation == nil {
+		if len(runRecommendations) == 0 {
 			webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d, no winner could be identified. This will happen when no pods could be assgined. No more runs are required, exiting early", runNumber))
 			break
 		}
-		if err := r.syncWinningResult(ctx, recommendation, winnerRunResult); err != nil {
-			return nil, err
+		if err := r.syncWinningResult(ctx, runRecommendations, winnerRunResult); err != nil {
+			webutil.InternalError(r.logWriter, err)
+			return recommendations, fmt.Errorf("cannot sync winning result of run #%d for shoot %s: %w", runNumber, r.shoot.Name, err)
 		}
-		webutil.Log(r.logWriter, fmt.Sprintf("For scale-up recommender run #%d, winning score is: %v", runNumber, recommendation))
-		recommendations = append(recommendations, *recommendation)
+		webutil.Log(r.logWriter, fmt.Sprintf("For scale-up recommender run #%d, winning score is: %v", runNumber, runRecommendations))
+		recommendations = append(recommendations, runRecommendations...)
 	}
 	return recommendations, nil
 }
 
-func (r *Recommender) getShoot() (*v1beta1.Shoot, error) {
-	shoot, err := r.engine.ShootAccess(r.shoot.Name).GetShootObj()
+func (r *Recommender) getShoot(ctx context.Context) (*v1beta1.Shoot, error) {
+	shoot, err := r.engine.ShootAccess(r.shoot.Name).GetShootObj(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -199,29 +250,58 @@ func (r *Recommender) computeCostRatiosForInstanceTypes(workerPools []v1beta1.Wo
 }
 
 func (r *Recommender) initializeSimulationState(ctx context.Context, shoot *v1beta1.Shoot, unscheduledPods []corev1.Pod) error {
-	r.state.unscheduledPods = unscheduledPods
-	r.state.originalPods = lo.SliceToMap[corev1.Pod, string, corev1.Pod](unscheduledPods, func(item corev1.Pod) (string, corev1.Pod) {
-		return item.Name, item
+	priorityClasses, err := r.engine.VirtualClusterAccess().ListPriorityClasses(ctx)
+	if err != nil {
+		return err
+	}
+	r.priorityClasses = priorityClasses
+	unscheduledPods = slices.Clone(unscheduledPods)
+	if err = simutil.ResolvePodPriorities(unscheduledPods, priorityClasses); err != nil {
+		return err
+	}
+	// like in the scheduling queue, pods of higher priority come first.
+	slices.SortStableFunc(unscheduledPods, func(a, b corev1.Pod) int {
+		return cmp.Compare(corev1helpers.PodPriority(&b), corev1helpers.PodPriority(&a))
 	})
+	if r.preemption {
+		unscheduledPods = r.dropExpendablePods(unscheduledPods)
+	}
+	r.state.unscheduledPods = unscheduledPods
 	return r.initializeEligibleNodePools(ctx, shoot)
 }
 
 func (r *Recommender) initializeEligibleNodePools(ctx context.Context, shoot *v1beta1.Shoot) error {
 	eligibleNodePools := make(map[string]scalesim.NodePool, len(shoot.Spec.Provider.Workers))
-	for _, worker := range shoot.Spec.Provider.Workers {
+	for i, worker := range shoot.Spec.Provider.Workers {
 		nodes, err := r.engine.VirtualClusterAccess().ListNodesInNodePool(ctx, worker.Name)
 		if err != nil {
 			return err
 		}
-		if int32(len(nodes)) >= worker.Maximum {
+		zoneLimits := simutil.ComputeZoneLimits(&worker)
+		zoneSizes := make(map[string]int32, len(worker.Zones))
+		var eligibleZones []string
+		for _, zone := range worker.Zones {
+			zoneSizes[zone] = simutil.ZoneSize(nodes, zone, zoneLimits[zone])
+			if zoneSizes[zone] < zoneLimits[zone].Max {
+				eligibleZones = append(eligibleZones, zone)
+			}
+		}
+		if len(eligibleZones) == 0 {
 			continue
 		}
 		nodePool := scalesim.NodePool{
 			Name:        worker.Name,
-			Zones:       worker.Zones,
+			Zones:       eligibleZones,
 			Max:         worker.Maximum,
 			Current:     int32(len(nodes)),
 			MachineType: worker.Machine.Type,
+			// pools declared first are preferred like in the cluster-autoscaler priority expander.
+			Priority:   len(shoot.Spec.Provider.Workers) - i,
+			ZoneLimits: zoneLimits,
+			ZoneSizes:  zoneSizes,
+		}
+		if priority, ok := r.nodePoolPriorities[worker.Name]; ok {
+			nodePool.Priority = priority
 		}
 		eligibleNodePools[worker.Name] = nodePool
 	}
@@ -229,35 +309,82 @@ func (r *Recommender) initializeEligibleNodePools(ctx context.Context, shoot *v1
 	return nil
 }
 
-// TODO: sync existing nodes and pods deployed on them. DO NOT TAINT THESE NODES.
-// eg:- 1 node(A) existing in zone a. Any node can only fit 2 pods.
-// deployment 6 replicas, tsc zone, minDomains 3
-// 1 pod will get assigned to A. 5 pending. 3 Nodes will be scale up. (1-a, 1-b, 1-c)
-// if you count existing nodes and pods, then only 2 nodes are needed.
-func (r *Recommender) runSimulation(ctx context.Context, runNum int) (*Recommendation, *runResult, error) {
-	/*
-		    1. initializeEligibleNodePools
-			2. For each nodePool, start a go routine. Each go routine will return a node score.
-			3. Collect the scores and return
-
-			Inside each go routine:-
-				1. Setup:-
-					 - create a unique label that will get added to all nodes and pods (for helping in clean up)
-				     - copy all nodes and add a taint.
-		             - copy the deployed pods with node names assigned and add a toleration to the taint.
-				2. For each zone in the nodePool:-
-					- scale up one node
-					- wait for assignment of pods (5 sec delay),
-					- calculate the score.
-			    	- Reset the state
-			    3. Compute the winning score for this nodePool and push to the result channel
-	*/
+// scheduleOnExistingNodes binds the unscheduled pods that fit onto the nodes of the virtual cluster before any node is
+// scaled up. Existing nodes synced together with their workload from the shoot are not tainted, so their free capacity
+// is used first. E.g. with one existing node in zone a that can host one more of the 2 pods that fit on any node, a
+// deployment of 6 replicas spread across 3 zones only needs 2 new nodes (1-b, 1-c) and not 3.
+func (r *Recommender) scheduleOnExistingNodes(ctx context.Context) error {
+	vca := r.engine.VirtualClusterAccess()
+	nodes, err := vca.ListNodes(ctx)
+	if err != nil || len(nodes) == 0 {
+		return err
+	}
+	candidatePods := r.createUnscheduledPodsForSimRun()
+	schedulingResults, err := vca.SchedulePods(ctx, r.podOrder, candidatePods...)
+	if err != nil {
+		return err
+	}
+	var pendingPods []corev1.Pod
+	for _, pod := range simutil.ApplySchedulingResults(candidatePods, schedulingResults) {
+		if pod.Spec.NodeName == "" {
+			pendingPods = append(pendingPods, pod)
+			continue
+		}
+		webutil.Log(r.logWriter, fmt.Sprintf("Pod %s fits on existing node %s, no scale-up needed for it", pod.Name, pod.Spec.NodeName))
+		r.state.scheduledPods = append(r.state.scheduledPods, pod)
+		r.state.unscheduledPods = slices.DeleteFunc(r.state.unscheduledPods, func(p corev1.Pod) bool {
+			return p.Name == pod.Name && p.Namespace == pod.Namespace
+		})
+	}
+	return vca.DeletePods(ctx, pendingPods...)
+}
+
+// runSimulation finds the winning scale-up of a run. The eligible node pools are simulated in tiers of descending
+// priority, the next tier is only simulated if no node pool of the current one can host a pending pod. Within a tier
+// every zone of every node pool is simulated concurrently:
+//   - fork the virtual cluster
+//   - scale up the estimated number of nodes for the unscheduled pods in the zone in the fork
+//   - schedule the unscheduled pods in the fork and remove the new nodes that stay empty
+//   - score the nodes that got pods and push the result to the result channel
+//
+// The best scored result wins and, with balancing, its nodes are distributed over the similar node groups.
+func (r *Recommender) runSimulation(ctx context.Context, runNum int) ([]Recommendation, *runResult, error) {
+	for _, nodePools := range r.nodePoolTiers() {
+		results, err := r.simulateNodePools(ctx, nodePools, runNum)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(results) == 0 {
+			if r.priorityMode {
+				webutil.Log(r.logWriter, fmt.Sprintf("No node pool of priority %d can host a pending pod, considering lower priorities", nodePools[0].Priority))
+			}
+			continue
+		}
+		recommendation, winnerRunResult := getWinner(results)
+		shutdownForks(results, winnerRunResult.fork)
+		if !r.balancing {
+			return []Recommendation{*recommendation}, &winnerRunResult, nil
+		}
+		recommendations, balancedRunResult, err := r.balanceWinner(ctx, winnerRunResult)
+		if err != nil {
+			winnerRunResult.fork.Shutdown()
+			return nil, nil, err
+		}
+		return recommendations, &balancedRunResult, nil
+	}
+	return nil, nil, nil
+}
 
+// simulateNodePools runs the simulations for all zones of the given node pools and returns the results that can win.
+func (r *Recommender) simulateNodePools(ctx context.Context, nodePools []scalesim.NodePool, runNum int) ([]runResult, error) {
 	var results []runResult
-	resultCh := make(chan runResult, len(r.state.eligibleNodePools))
-	r.triggerNodePoolSimulations(ctx, resultCh, runNum)
+	numRuns := 0
+	for _, nodePool := range nodePools {
+		numRuns += len(nodePool.Zones)
+	}
+	resultCh := make(chan runResult, numRuns)
+	r.triggerNodePoolSimulations(ctx, nodePools, resultCh, runNum)
 
-	// label, taint, result chan, error chan, close chan
 	var errs error
 	for result := range resultCh {
 		if result.err != nil {
@@ -269,223 +396,220 @@ func (r *Recommender) runSimulation(ctx context.Context, runNum int) (*Recommend
 		}
 	}
 	if errs != nil {
-		return nil, nil, errs
+		shutdownForks(results, nil)
+		return nil, errs
 	}
+	return results, nil
+}
 
-	recommendation, winnerRunResult := getWinner(results)
-	return recommendation, &winnerRunResult, nil
+// shutdownForks shuts down the forks of all run results except the given one which is still needed.
+func shutdownForks(results []runResult, keep scalesim.VirtualClusterAccess) {
+	for _, result := range results {
+		if result.fork != nil && result.fork != keep {
+			result.fork.Shutdown()
+		}
+	}
 }
 
-func (r *Recommender) syncWinningResult(ctx context.Context, recommendation *Recommendation, winningRunResult *runResult) error {
+func (r *Recommender) syncWinningResult(ctx context.Context, recommendations []Recommendation, winningRunResult *runResult) error {
 	startTime := time.Now()
 	defer func() {
-		webutil.Log(r.logWriter, fmt.Sprintf("syncWinningResult for nodePool: %s completed in %f seconds", recommendation.nodePoolName, time.Since(startTime).Seconds()))
+		webutil.Log(r.logWriter, fmt.Sprintf("syncWinningResult for %s completed in %f seconds", RecommendationsString(recommendations), time.Since(startTime).Seconds()))
 	}()
-	scheduledPodNames, err := r.syncClusterWithWinningResult(ctx, winningRunResult)
+	scheduledPodKeys, err := r.syncClusterWithWinningResult(ctx, winningRunResult)
 	if err != nil {
 		return err
 	}
-	return r.syncRecommenderStateWithWinningResult(ctx, recommendation, winningRunResult.nodeName, scheduledPodNames)
+	return r.syncRecommenderStateWithWinningResult(ctx, recommendations, winningRunResult.nodeNames, scheduledPodKeys)
 }
 
-func (r *Recommender) syncClusterWithWinningResult(ctx context.Context, winningRunResult *runResult) ([]string, error) {
-	node, err := r.constructNodeFromExistingNodeOfInstanceType(winningRunResult.instanceType, winningRunResult.nodePoolName, winningRunResult.zone, false, nil)
-	if err != nil {
+func (r *Recommender) syncClusterWithWinningResult(ctx context.Context, winningRunResult *runResult) ([]types.NamespacedName, error) {
+	defer winningRunResult.fork.Shutdown()
+	if err := r.engine.VirtualClusterAccess().Merge(ctx, winningRunResult.fork); err != nil {
 		return nil, err
 	}
-	node.Name = winningRunResult.nodeName
-	var originalPods []corev1.Pod
-	var scheduledPods []corev1.Pod
-	for nodeName, simPodObjectKeys := range winningRunResult.nodeToPods {
-		for _, simPodObjectKey := range simPodObjectKeys {
-			podName := toOriginalResourceName(simPodObjectKey.Name)
-			pod, ok := r.state.originalPods[podName]
-			if !ok {
-				return nil, fmt.Errorf("unexpected error, pod: %s not found in the original pods collection", podName)
-			}
-			originalPods = append(originalPods, pod)
-			podCopy := pod.DeepCopy()
-			podCopy.Spec.NodeName = toOriginalResourceName(nodeName)
-			podCopy.ObjectMeta.ResourceVersion = ""
-			podCopy.ObjectMeta.CreationTimestamp = metav1.Time{}
-			scheduledPods = append(scheduledPods, *podCopy)
+	for _, nodeName := range winningRunResult.nodeNames {
+		if err := r.engine.VirtualClusterAccess().RemoveTaintFromVirtualNode(ctx, nodeName, corev1.TaintNodeNotReady); err != nil {
+			return nil, err
 		}
 	}
-	if err = r.engine.VirtualClusterAccess().AddPods(ctx, scheduledPods...); err != nil {
-		return nil, err
-	}
-	if err = r.engine.VirtualClusterAccess().AddNodes(ctx, node); err != nil {
-		return nil, err
-	}
-	if err = r.engine.VirtualClusterAccess().RemoveTaintFromVirtualNode(ctx, node.Name, "node.kubernetes.io/not-ready"); err != nil {
-		return nil, err
+	var scheduledPodKeys []types.NamespacedName
+	for _, podObjectKeys := range winningRunResult.nodeToPods {
+		scheduledPodKeys = append(scheduledPodKeys, podObjectKeys...)
 	}
-	return simutil.PodNames(scheduledPods), nil
+	return scheduledPodKeys, nil
 }
 
-func (r *Recommender) syncRecommenderStateWithWinningResult(ctx context.Context, recommendation *Recommendation, winningNodeName string, scheduledPodNames []string) error {
-	winnerNode, err := r.engine.VirtualClusterAccess().GetNode(ctx, types.NamespacedName{Name: winningNodeName, Namespace: "default"})
-	if err != nil {
-		return err
-	}
-	r.state.existingNodes = append(r.state.existingNodes, *winnerNode)
-	scheduledPods, err := r.engine.VirtualClusterAccess().ListPodsMatchingPodNames(ctx, "default", scheduledPodNames)
-	if err != nil {
-		return err
+func (r *Recommender) syncRecommenderStateWithWinningResult(ctx context.Context, recommendations []Recommendation, winningNodeNames []string, scheduledPodKeys []types.NamespacedName) error {
+	for _, nodeName := range winningNodeNames {
+		winnerNode, err := r.engine.VirtualClusterAccess().GetNode(ctx, types.NamespacedName{Name: nodeName, Namespace: "default"})
+		if err != nil {
+			return err
+		}
+		r.state.scaledUpNodes = append(r.state.scaledUpNodes, *winnerNode)
 	}
-	for _, pod := range scheduledPods {
-		r.state.scheduledPods = append(r.state.scheduledPods, pod)
+	for _, podKey := range scheduledPodKeys {
+		pod, err := r.engine.VirtualClusterAccess().GetPod(ctx, podKey)
+		if err != nil {
+			return err
+		}
+		r.state.scheduledPods = append(r.state.scheduledPods, *pod)
 		r.state.unscheduledPods = slices.DeleteFunc(r.state.unscheduledPods, func(p corev1.Pod) bool {
-			return p.Name == pod.Name
+			return p.Name == pod.Name && p.Namespace == pod.Namespace
 		})
 	}
-	r.state.updateEligibleNodePools(recommendation)
+	for i := range recommendations {
+		r.state.updateEligibleNodePools(&recommendations[i])
+	}
 	return nil
 }
 
-func (r *Recommender) triggerNodePoolSimulations(ctx context.Context, resultCh chan runResult, runNum int) {
+func (r *Recommender) triggerNodePoolSimulations(ctx context.Context, nodePools []scalesim.NodePool, resultCh chan runResult, runNum int) {
 	wg := &sync.WaitGroup{}
 	logger := webutil.NewLogger()
-	logger.Log(r.logWriter, fmt.Sprintf("Starting simulation runs for %v nodePools", maps.Keys(r.state.eligibleNodePools)))
-	for _, nodePool := range r.state.eligibleNodePools {
+	logger.Log(r.logWriter, fmt.Sprintf("Starting simulation runs for %v nodePools", lo.Map(nodePools, func(nodePool scalesim.NodePool, _ int) string {
+		return nodePool.Name
+	})))
+	for _, nodePool := range nodePools {
 		wg.Add(1)
-		runRef := simRunRef{
-			key:   "app.kubernetes.io/simulation-run",
-			value: nodePool.Name + "-" + strconv.Itoa(runNum),
-		}
-		go r.runSimulationForNodePool(ctx, logger, wg, nodePool, resultCh, runRef)
+		go r.runSimulationForNodePool(ctx, logger, wg, nodePool, resultCh, nodePool.Name+"-"+strconv.Itoa(runNum))
 	}
 	wg.Wait()
 	close(resultCh)
 }
 
-func (r *Recommender) runSimulationForNodePool(ctx context.Context, logger *webutil.Logger, wg *sync.WaitGroup, nodePool scalesim.NodePool, resultCh chan runResult, runRef simRunRef) {
+func (r *Recommender) runSimulationForNodePool(ctx context.Context, logger *webutil.Logger, wg *sync.WaitGroup, nodePool scalesim.NodePool, resultCh chan runResult, runName string) {
 	simRunStartTime := time.Now()
 	defer wg.Done()
 	defer func() {
-		logger.Log(r.logWriter, fmt.Sprintf("Simulation run: %s for nodePool: %s completed in %f seconds", runRef.value, nodePool.Name, time.Since(simRunStartTime).Seconds()))
-	}()
-	defer func() {
-		if err := r.cleanUpNodePoolSimRun(ctx, runRef); err != nil {
-			// In the productive code, there will not be any real KAPI and ETCD. Fake API server will never return an error as everything will be in memory.
-			// For now, we are only logging this error as in the POC code since the caller of recommender will re-initialize the virtual cluster.
-			logger.Log(r.logWriter, "Error cleaning up simulation run: "+runRef.value+" err: "+err.Error())
-		}
+		logger.Log(r.logWriter, fmt.Sprintf("Simulation run: %s for nodePool: %s completed in %f seconds", runName, nodePool.Name, time.Since(simRunStartTime).Seconds()))
 	}()
-	var (
-		node *corev1.Node
-		err  error
-	)
-	// create a copy of all nodes and scheduled pods only
-	if err = r.setupSimulationRun(ctx, runRef); err != nil {
-		resultCh <- createErrorResult(err)
-		return
-	}
 	for _, zone := range nodePool.Zones {
-		if node != nil {
-			if err = r.resetNodePoolSimRun(ctx, node.Name, runRef); err != nil {
-				resultCh <- createErrorResult(err)
-				return
-			}
-		}
-		node, err = r.constructNodeFromExistingNodeOfInstanceType(nodePool.MachineType, nodePool.Name, zone, true, &runRef)
+		resultCh <- r.runSimulationForNodePoolZone(ctx, nodePool, zone)
+	}
+}
+
+// runSimulationForNodePoolZone scales up the estimated number of nodes of the nodePool in the given zone in a fork of
+// the virtual cluster and schedules the unscheduled pods in it. New nodes that stay empty are removed from the fork
+// again, so that the estimate is validated in a single step. The fork is kept in the result if it can be the winner.
+func (r *Recommender) runSimulationForNodePoolZone(ctx context.Context, nodePool scalesim.NodePool, zone string) runResult {
+	template, err := r.constructNode(nodePool, zone)
+	if err != nil {
+		return createErrorResult(err)
+	}
+	capacity := r.workloadCapacity(template)
+	if !r.anyUnscheduledPodFits(capacity) {
+		return runResult{}
+	}
+	nodes := []*corev1.Node{template}
+	maxNodes := nodePool.ZoneLimits[zone].Max - nodePool.ZoneSizes[zone]
+	for numNodes := estimateNodeCount(capacity, r.state.unscheduledPods, maxNodes); int32(len(nodes)) < numNodes; {
+		node, err := r.constructNode(nodePool, zone)
 		if err != nil {
-			resultCh <- createErrorResult(err)
-			return
+			return createErrorResult(err)
 		}
-		if err = r.engine.VirtualClusterAccess().AddNodes(ctx, node); err != nil {
-			resultCh <- createErrorResult(err)
-			return
+		nodes = append(nodes, node)
+	}
+	fork, simRunCandidatePods, usedNodes, err := r.simulateScaleUp(ctx, nodes)
+	if err != nil {
+		return createErrorResult(err)
+	}
+	if len(usedNodes) == 0 {
+		fork.Shutdown()
+		return runResult{}
+	}
+	ns := r.computeNodeScore(nodePool, zone, usedNodes, simRunCandidatePods)
+	result := r.computeRunResult(nodePool.Name, nodePool.MachineType, zone, usedNodes, ns, simRunCandidatePods)
+	if !result.HasWinner() {
+		fork.Shutdown()
+		return result
+	}
+	result.fork = fork
+	return result
+}
+
+// simulateScaleUp adds the nodes with their DaemonSet pods to a fork of the virtual cluster and schedules the
+// unscheduled pods in it. New nodes that stay empty are removed from the fork again. It returns the fork, the pods
+// with their assigned nodes and the nodes that got pods assigned.
+func (r *Recommender) simulateScaleUp(ctx context.Context, nodes []*corev1.Node) (scalesim.VirtualClusterAccess, []corev1.Pod, []*corev1.Node, error) {
+	fork, err := r.engine.VirtualClusterAccess().Fork(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = fork.AddNodes(ctx, nodes...); err != nil {
+		fork.Shutdown()
+		return nil, nil, nil, err
+	}
+	for _, node := range nodes {
+		if err = fork.AddPods(ctx, r.daemonSetPodsForNode(node)...); err != nil {
+			fork.Shutdown()
+			return nil, nil, nil, err
 		}
-		if err = r.engine.VirtualClusterAccess().RemoveTaintFromVirtualNodes(ctx, "node.kubernetes.io/not-ready"); err != nil {
-			return
+	}
+	unscheduledPods := r.createUnscheduledPodsForSimRun()
+	schedulingResults, err := fork.SchedulePods(ctx, "", unscheduledPods...)
+	if err != nil {
+		fork.Shutdown()
+		return nil, nil, nil, err
+	}
+	pods := simutil.ApplySchedulingResults(unscheduledPods, schedulingResults)
+	usedNodes, err := removeEmptyNodes(ctx, fork, nodes, pods)
+	if err != nil {
+		fork.Shutdown()
+		return nil, nil, nil, err
+	}
+	return fork, pods, usedNodes, nil
+}
+
+// removeEmptyNodes deletes the nodes that none of the pods was assigned to together with their DaemonSet pods from the
+// fork and returns the others.
+func removeEmptyNodes(ctx context.Context, fork scalesim.VirtualClusterAccess, nodes []*corev1.Node, pods []corev1.Pod) ([]*corev1.Node, error) {
+	usedNodes := make([]*corev1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if countPodsOnNode(pods, node.Name) > 0 {
+			usedNodes = append(usedNodes, node)
+			continue
 		}
-		deployTime := time.Now()
-		unscheduledPods, err := r.createAndDeployUnscheduledPods(ctx, runRef)
+		dsPods, err := simutil.GetPodsOnNode(ctx, fork, node.Name)
 		if err != nil {
-			return
+			return nil, err
 		}
-		// in production code FAKE KAPI will not return any error. This is only for POC code where an envtest KAPI is used.
-		_, _, err = simutil.WaitForAndRecordPodSchedulingEvents(ctx, r.engine.VirtualClusterAccess(), r.logWriter, deployTime, unscheduledPods, 10*time.Second)
-		if err != nil {
-			resultCh <- createErrorResult(err)
-			return
+		if err = fork.DeletePods(ctx, dsPods...); err != nil {
+			return nil, err
 		}
-		simRunCandidatePods, err := r.engine.VirtualClusterAccess().GetPods(ctx, "default", simutil.PodNames(unscheduledPods))
-		//simRunCandidatePods, err := r.engine.VirtualClusterAccess().ListPodsMatchingLabels(ctx, runRef.asMap())
-		if err != nil {
-			resultCh <- createErrorResult(err)
-			return
+		if err = fork.DeleteNode(ctx, node.Name); err != nil {
+			return nil, err
 		}
-		ns := r.computeNodeScore(node, simRunCandidatePods)
-		resultCh <- r.computeRunResult(nodePool.Name, nodePool.MachineType, zone, node.Name, ns, simRunCandidatePods)
 	}
+	return usedNodes, nil
 }
 
-func (r *Recommender) setupSimulationRun(ctx context.Context, runRef simRunRef) error {
-	// create copy of all nodes (barring existing nodes)
-	clonedNodes := make([]*corev1.Node, 0, len(r.state.existingNodes))
-	for _, node := range r.state.existingNodes {
-		nodeCopy := node.DeepCopy()
-		nodeCopy.Name = fromOriginalResourceName(nodeCopy.Name, runRef.value)
-		nodeCopy.Labels[runRef.key] = runRef.value
-		nodeCopy.Labels["kubernetes.io/hostname"] = nodeCopy.Name
-		nodeCopy.ObjectMeta.UID = ""
-		nodeCopy.ObjectMeta.ResourceVersion = ""
-		nodeCopy.ObjectMeta.CreationTimestamp = metav1.Time{}
-		nodeCopy.Spec.Taints = []corev1.Taint{
-			{Key: runRef.key, Value: runRef.value, Effect: corev1.TaintEffectNoSchedule},
-		}
-		clonedNodes = append(clonedNodes, nodeCopy)
-	}
-	if err := r.engine.VirtualClusterAccess().AddNodes(ctx, clonedNodes...); err != nil {
-		return err
-	}
-
-	// create copy of all scheduled pods
-	clonedScheduledPods := make([]corev1.Pod, 0, len(r.state.scheduledPods))
-	for _, pod := range r.state.scheduledPods {
-		podCopy := pod.DeepCopy()
-		podCopy.Name = fromOriginalResourceName(podCopy.Name, runRef.value)
-		podCopy.Labels[runRef.key] = runRef.value
-		podCopy.ObjectMeta.UID = ""
-		podCopy.ObjectMeta.ResourceVersion = ""
-		podCopy.ObjectMeta.CreationTimestamp = metav1.Time{}
-		podCopy.Spec.Tolerations = []corev1.Toleration{
-			{Key: runRef.key, Value: runRef.value, Effect: corev1.TaintEffectNoSchedule, Operator: corev1.TolerationOpEqual},
-		}
-		if len(podCopy.Spec.TopologySpreadConstraints) > 0 {
-			updatedTSC := make([]corev1.TopologySpreadConstraint, 0, len(podCopy.Spec.TopologySpreadConstraints))
-			for _, tsc := range podCopy.Spec.TopologySpreadConstraints {
-				tsc.LabelSelector.MatchLabels[runRef.key] = runRef.value
-				updatedTSC = append(updatedTSC, tsc)
-			}
-			podCopy.Spec.TopologySpreadConstraints = updatedTSC
+// anyUnscheduledPodFits checks whether the node offers enough of every resource for at least one unscheduled pod,
+// which saves forking the virtual cluster for node pools that cannot win.
+func (r *Recommender) anyUnscheduledPodFits(node *corev1.Node) bool {
+	for i := range r.state.unscheduledPods {
+		if len(resutil.InsufficientResources(node, &r.state.unscheduledPods[i])) == 0 {
+			return true
 		}
-		podCopy.Spec.NodeName = fromOriginalResourceName(podCopy.Spec.NodeName, runRef.value)
-		clonedScheduledPods = append(clonedScheduledPods, *podCopy)
 	}
-	if err := r.engine.VirtualClusterAccess().AddPods(ctx, clonedScheduledPods...); err != nil {
-		return err
-	}
-	return nil
+	return false
 }
 
-func (r *Recommender) resetNodePoolSimRun(ctx context.Context, nodeName string, runRef simRunRef) error {
-	// remove the node with the nodeName
-	if err := r.engine.VirtualClusterAccess().DeleteNode(ctx, nodeName); err != nil {
-		return err
+// constructNode constructs a node of the node pool in the given zone from its worker spec and an existing node of the
+// instance type. Without such a node, e.g. for pools scaled to zero, the node is synthesized from the worker spec.
+func (r *Recommender) constructNode(nodePool scalesim.NodePool, zone string) (*corev1.Node, error) {
+	worker, ok := lo.Find(r.shoot.Spec.Provider.Workers, func(worker v1beta1.Worker) bool {
+		return worker.Name == nodePool.Name
+	})
+	if !ok {
+		return nil, fmt.Errorf("worker pool %q not found in shoot %q", nodePool.Name, r.shoot.Name)
 	}
-	// remove the pods with nodeName
-	pods, err := r.engine.VirtualClusterAccess().ListPodsMatchingLabels(ctx, runRef.asMap())
-	if err != nil {
-		return err
+	// without a reference node the node is built from the machine type of the worker pool alone.
+	referenceNode, err := r.engine.VirtualClusterAccess().GetReferenceNode(nodePool.MachineType)
+	if err != nil && !errors.Is(err, scalesim.ErrReferenceNodeNotFound) {
+		return nil, err
 	}
-	return r.engine.VirtualClusterAccess().DeletePods(ctx, pods...)
-}
-
-func (r *Recommender) constructNodeFromExistingNodeOfInstanceType(instanceType, poolName, zone string, forSimRun bool, runRef *simRunRef) (*corev1.Node, error) {
-	referenceNode, err := r.engine.VirtualClusterAccess().GetReferenceNode(instanceType)
+	node, err := simutil.NewWorkerNode(r.shoot, &worker, referenceNode, zone)
 	if err != nil {
 		return nil, err
 	}
@@ -493,65 +617,23 @@ func (r *Recommender) constructNodeFromExistingNodeOfInstanceType(instanceType,
 	if err != nil {
 		return nil, err
 	}
-	nodeLabels := referenceNode.Labels
-	nodeLabels["topology.kubernetes.io/zone"] = zone
-	var nodeName string
-	taints := make([]corev1.Taint, 0, 1)
-	if forSimRun {
-		nodeName = nodeNamePrefix + "-" + poolName + "-simrun-" + runRef.value
-		nodeLabels[runRef.key] = runRef.value
-		taints = append(taints, corev1.Taint{Key: runRef.key, Value: runRef.value, Effect: corev1.TaintEffectNoSchedule})
-	} else {
-		nodeName = nodeNamePrefix + "-" + poolName
-	}
-	nodeLabels["kubernetes.io/hostname"] = nodeName
-	delete(nodeLabels, "app.kubernetes.io/existing-node")
-
-	node := &corev1.Node{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      nodeName,
-			Namespace: "default",
-			Labels:    nodeLabels,
-		},
-		Spec: corev1.NodeSpec{
-			Taints: taints,
-		},
-		Status: corev1.NodeStatus{
-			Allocatable: referenceNode.Status.Allocatable,
-			Capacity:    referenceNode.Status.Capacity,
-			Phase:       corev1.NodeRunning,
-		},
-	}
+	node.Name = nodeNamePrefix + "-" + nodePool.Name
+	node.Namespace = "default"
+	node.Labels["kubernetes.io/hostname"] = node.Name
 	return node, nil
 }
 
-func (r *Recommender) createAndDeployUnscheduledPods(ctx context.Context, runRef simRunRef) ([]corev1.Pod, error) {
+func (r *Recommender) createUnscheduledPodsForSimRun() []corev1.Pod {
 	unscheduledPodList := make([]corev1.Pod, 0, len(r.state.unscheduledPods))
 	for _, pod := range r.state.unscheduledPods {
 		podCopy := pod.DeepCopy()
-		podCopy.Name = fromOriginalResourceName(podCopy.Name, runRef.value)
-		podCopy.Labels[runRef.key] = runRef.value
-		podCopy.ObjectMeta.UID = ""
-		podCopy.ObjectMeta.ResourceVersion = ""
-		podCopy.ObjectMeta.CreationTimestamp = metav1.Time{}
-		podCopy.Spec.Tolerations = []corev1.Toleration{
-			{Key: runRef.key, Value: runRef.value, Effect: corev1.TaintEffectNoSchedule, Operator: corev1.TolerationOpEqual},
-		}
-		if len(podCopy.Spec.TopologySpreadConstraints) > 0 {
-			updatedTSC := make([]corev1.TopologySpreadConstraint, 0, len(podCopy.Spec.TopologySpreadConstraints))
-			for _, tsc := range podCopy.Spec.TopologySpreadConstraints {
-				tsc.LabelSelector.MatchLabels[runRef.key] = runRef.value
-				updatedTSC = append(updatedTSC, tsc)
-			}
-			podCopy.Spec.TopologySpreadConstraints = updatedTSC
-		}
 		podCopy.Spec.SchedulerName = virtualcluster.BinPackingSchedulerName
 		unscheduledPodList = append(unscheduledPodList, *podCopy)
 	}
-	return unscheduledPodList, r.engine.VirtualClusterAccess().AddPods(ctx, unscheduledPodList...)
+	return unscheduledPodList
 }
 
-func (r *Recommender) computeRunResult(nodePoolName, instanceType, zone, nodeName string, score nodeScore, pods []corev1.Pod) runResult {
+func (r *Recommender) computeRunResult(nodePoolName, instanceType, zone string, nodes []*corev1.Node, score nodeScore, pods []corev1.Pod) runResult {
 	if score.unscheduledRatio == 1.0 {
 		return runResult{}
 	}
@@ -565,8 +647,10 @@ func (r *Recommender) computeRunResult(nodePoolName, instanceType, zone, nodeNam
 		}
 	}
 	return runResult{
-		nodePoolName:    nodePoolName,
-		nodeName:        toOriginalResourceName(nodeName),
+		nodePoolName: nodePoolName,
+		nodeNames: lo.Map(nodes, func(node *corev1.Node, _ int) string {
+			return node.Name
+		}),
 		zone:            zone,
 		instanceType:    instanceType,
 		nodeScore:       score,
@@ -575,28 +659,40 @@ func (r *Recommender) computeRunResult(nodePoolName, instanceType, zone, nodeNam
 	}
 }
 
-func (r *Recommender) computeNodeScore(scaledNode *corev1.Node, candidatePods []corev1.Pod) nodeScore {
-	costRatio := r.strategyWeights.LeastCost * r.instanceTypeCostRatios[scaledNode.Labels["node.kubernetes.io/instance-type"]]
-	memoryWasteRatio := r.strategyWeights.LeastWaste * computeMemoryWasteRatio(scaledNode, candidatePods)
-	cpuWasteRatio := r.strategyWeights.LeastWaste + computeCPUWasteRatio(scaledNode, candidatePods) //Using the same `LeastWaste` weight. Should we consider having different weights for different resources?
+// computeNodeScore scores the nodes scaled up in a run, which are all of the same instance type, together. Only the
+// capacity left by DaemonSet pods is taken into account. The cost ratio adds up the ratios of all nodes, so that runs
+// with different increments are compared by their total price.
+func (r *Recommender) computeNodeScore(nodePool scalesim.NodePool, zone string, scaledNodes []*corev1.Node, candidatePods []corev1.Pod) nodeScore {
+	scaledNodes = lo.Map(scaledNodes, func(node *corev1.Node, _ int) *corev1.Node {
+		return r.workloadCapacity(node)
+	})
+	costRatio := lo.SumBy(scaledNodes, func(node *corev1.Node) float64 {
+		return r.instanceTypeCostRatios[node.Labels["node.kubernetes.io/instance-type"]]
+	})
+	wasteRatios := resutil.TotalWasteRatios(scaledNodes, candidatePods, resutil.NodeResources(scaledNodes[0]))
+	wasteRatio := resutil.WeightedWaste(wasteRatios, resutil.EffectiveWeights(r.strategyWeights.ResourceWeights, candidatePods))
 	unscheduledRatio := computeUnscheduledRatio(candidatePods)
-	cumulativeScore := ((memoryWasteRatio + cpuWasteRatio) / 2) + unscheduledRatio*costRatio
+	cumulativeScore := r.scorer.Score(scorer.Run{
+		NodePoolName:     nodePool.Name,
+		InstanceType:     nodePool.MachineType,
+		Zone:             zone,
+		Priority:         nodePool.Priority,
+		WasteRatio:       wasteRatio,
+		CostRatio:        costRatio,
+		UnscheduledRatio: unscheduledRatio,
+		NumAssignedPods: lo.SumBy(scaledNodes, func(node *corev1.Node) int {
+			return countPodsOnNode(candidatePods, node.Name)
+		}),
+	})
 	return nodeScore{
-		memoryWasteRatio: memoryWasteRatio,
-		cpuWasteRatio:    cpuWasteRatio,
+		wasteRatios:      wasteRatios,
+		wasteRatio:       wasteRatio,
 		unscheduledRatio: unscheduledRatio,
 		costRatio:        costRatio,
 		cumulativeScore:  cumulativeScore,
 	}
 }
 
-func (r *Recommender) cleanUpNodePoolSimRun(ctx context.Context, runRef simRunRef) error {
-	labels := runRef.asMap()
-	err := r.engine.VirtualClusterAccess().DeletePodsWithMatchingLabels(ctx, labels)
-	err = r.engine.VirtualClusterAccess().DeleteNodesWithMatchingLabels(ctx, labels)
-	return err
-}
-
 func getWinner(results []runResult) (*Recommendation, runResult) {
 	if len(results) == 0 {
 		return nil, runResult{}
@@ -618,12 +714,17 @@ func getWinner(results []runResult) (*Recommendation, runResult) {
 	rand.Seed(uint64(time.Now().UnixNano()))
 	winningIndex := rand.Intn(len(winningRunResults))
 	winner = winningRunResults[winningIndex]
-	return &Recommendation{
-		zone:         winner.zone,
-		nodePoolName: winner.nodePoolName,
-		incrementBy:  int32(1),
-		instanceType: winner.instanceType,
-	}, winner
+	recommendation := newRecommendation(winner)
+	return &recommendation, winner
+}
+
+func newRecommendation(result runResult) Recommendation {
+	return Recommendation{
+		zone:         result.zone,
+		nodePoolName: result.nodePoolName,
+		incrementBy:  int32(len(result.nodeNames)),
+		instanceType: result.instanceType,
+	}
 }
 
 func createErrorResult(err error) runResult {
@@ -636,6 +737,16 @@ func (r *Recommender) logError(err error) {
 	webutil.Log(r.logWriter, "Execution of scenario: "+r.scenarioName+" completed with error: "+err.Error())
 }
 
+func countPodsOnNode(pods []corev1.Pod, nodeName string) int {
+	var count int
+	for _, pod := range pods {
+		if pod.Spec.NodeName == nodeName {
+			count++
+		}
+	}
+	return count
+}
+
 func computeUnscheduledRatio(candidatePods []corev1.Pod) float64 {
 	var totalAssignedPods int
 	for _, pod := range candidatePods {
@@ -645,55 +756,3 @@ func computeUnscheduledRatio(candidatePods []corev1.Pod) float64 {
 	}
 	return float64(len(candidatePods)-totalAssignedPods) / float64(len(candidatePods))
 }
-
-func computeMemoryWasteRatio(node *corev1.Node, candidatePods []corev1.Pod) float64 {
-	var (
-		//targetNodeAssignedPods []corev1.Pod
-		totalMemoryConsumed int64
-	)
-	for _, pod := range candidatePods {
-		if pod.Spec.NodeName == node.Name {
-			//targetNodeAssignedPods = append(targetNodeAssignedPods, pod)
-			var podMemoryConsumed int64
-			for _, container := range pod.Spec.Containers {
-				containerResources, ok := container.Resources.Requests[corev1.ResourceMemory]
-				if ok {
-					podMemoryConsumed += containerResources.MilliValue()
-				}
-			}
-			slog.Info("NodPodAssignment: ", "pod", pod.Name, "node", pod.Spec.NodeName, "memory", podMemoryConsumed)
-			totalMemoryConsumed += podMemoryConsumed
-		}
-	}
-	totalMemoryCapacity := node.Status.Capacity.Memory().MilliValue()
-	return float64(totalMemoryCapacity-totalMemoryConsumed) / float64(totalMemoryCapacity)
-}
-
-func computeCPUWasteRatio(node *corev1.Node, candidatePods []corev1.Pod) float64 {
-	var (
-		totalCPUUsage int64
-	)
-	for _, pod := range candidatePods {
-		if pod.Spec.NodeName == node.Name {
-			var podCPUUsage int64
-			for _, container := range pod.Spec.Containers {
-				containerResources, ok := container.Resources.Requests[corev1.ResourceCPU]
-				if ok {
-					podCPUUsage += containerResources.MilliValue()
-				}
-			}
-			slog.Info("NodPodAssignment: ", "pod", pod.Name, "node", pod.Spec.NodeName, "cpu", podCPUUsage)
-			totalCPUUsage += podCPUUsage
-		}
-	}
-	totalCPUCapacity := node.Status.Capacity.Cpu().MilliValue()
-	return float64(totalCPUCapacity-totalCPUUsage) / float64(totalCPUCapacity)
-}
-
-func fromOriginalResourceName(name, suffix string) string {
-	return fmt.Sprintf(resourceNameFormat, name, suffix)
-}
-
-func toOriginalResourceName(simResName string) string {
-	return strings.Split(simResName, "-simrun-")[0]
-}
@@ -0,0 +1,60 @@
+package recommender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/scorer"
+)
+
+func TestComputeNodeScoreAppliesResourceWeights(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"node.kubernetes.io/instance-type": "m5.large"}},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	pods := []corev1.Pod{{Spec: corev1.PodSpec{NodeName: node.Name, Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	}}}}}}}
+	nodePool := scalesim.NodePool{Name: "p1", MachineType: "m5.large"}
+	r := &Recommender{instanceTypeCostRatios: map[string]float64{"m5.large": 1}}
+
+	r.scorer = scorer.Composite{LeastWasteWeight: 0, LeastCostWeight: 1}
+	assert.Equal(t, 0.0, r.computeNodeScore(nodePool, "a", []*corev1.Node{node}, pods).cumulativeScore)
+
+	r.scorer = scorer.Composite{LeastWasteWeight: 1, LeastCostWeight: 1}
+	assert.InDelta(t, 0.375, r.computeNodeScore(nodePool, "a", []*corev1.Node{node}, pods).cumulativeScore, 1e-9)
+
+	r.strategyWeights = StrategyWeights{ResourceWeights: map[corev1.ResourceName]float64{corev1.ResourceCPU: 3}}
+	score := r.computeNodeScore(nodePool, "a", []*corev1.Node{node}, pods)
+	assert.InDelta(t, 0.1875, score.cumulativeScore, 1e-9)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 0, corev1.ResourceMemory: 0.75}, score.wasteRatios)
+}
+
+func TestComputeNodeScoreComparesTotalPrice(t *testing.T) {
+	newNode := func(name, instanceType, cpu string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node.kubernetes.io/instance-type": instanceType}},
+			Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}
+	}
+	r := &Recommender{
+		instanceTypeCostRatios: map[string]float64{"m5.large": 0.2, "m5.2xlarge": 0.4},
+		scorer:                 scorer.Price{},
+	}
+	small := []*corev1.Node{newNode("s1", "m5.large", "2"), newNode("s2", "m5.large", "2"), newNode("s3", "m5.large", "2")}
+	large := []*corev1.Node{newNode("l1", "m5.2xlarge", "8")}
+
+	smallScore := r.computeNodeScore(scalesim.NodePool{Name: "small", MachineType: "m5.large"}, "a", small, nil)
+	largeScore := r.computeNodeScore(scalesim.NodePool{Name: "large", MachineType: "m5.2xlarge"}, "a", large, nil)
+	assert.InDelta(t, 0.6, smallScore.costRatio, 1e-9)
+	assert.Less(t, largeScore.cumulativeScore, smallScore.cumulativeScore)
+}
@@ -0,0 +1,177 @@
+package resutil
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+)
+
+// Resource is one dimension of the resource model that scale-up recommendations are scored with.
+type Resource interface {
+	// Name returns the name of the resource, e.g. cpu, ephemeral-storage or nvidia.com/gpu.
+	Name() corev1.ResourceName
+	// Allocatable returns the amount of the resource that the node offers to pods.
+	Allocatable(node *corev1.Node) int64
+	// Request returns the amount of the resource that the pod occupies on its node.
+	Request(pod *corev1.Pod) int64
+}
+
+// requestResource is a Resource that pods request through their containers, like cpu, memory, ephemeral-storage,
+// hugepages or extended resources. Amounts are in milli units so that cpu fractions are not lost.
+type requestResource struct {
+	name corev1.ResourceName
+}
+
+func (r requestResource) Name() corev1.ResourceName {
+	return r.name
+}
+
+func (r requestResource) Allocatable(node *corev1.Node) int64 {
+	quantity, ok := node.Status.Allocatable[r.name]
+	if !ok {
+		quantity = node.Status.Capacity[r.name]
+	}
+	return quantity.MilliValue()
+}
+
+func (r requestResource) Request(pod *corev1.Pod) int64 {
+	quantity := PodRequests(pod)[r.name]
+	return quantity.MilliValue()
+}
+
+// podsResource is the max-pods limit of a node. Every pod occupies one slot of it.
+type podsResource struct{}
+
+func (podsResource) Name() corev1.ResourceName {
+	return corev1.ResourcePods
+}
+
+func (podsResource) Allocatable(node *corev1.Node) int64 {
+	quantity, ok := node.Status.Allocatable[corev1.ResourcePods]
+	if !ok {
+		quantity = node.Status.Capacity[corev1.ResourcePods]
+	}
+	return quantity.Value()
+}
+
+func (podsResource) Request(*corev1.Pod) int64 {
+	return 1
+}
+
+// NewResource returns the Resource for the given resource name.
+func NewResource(name corev1.ResourceName) Resource {
+	if name == corev1.ResourcePods {
+		return podsResource{}
+	}
+	return requestResource{name: name}
+}
+
+// PodRequests returns the effective requests of the pod the way the kube-scheduler accounts them: the larger of the
+// sum of the containers and the largest init container, including sidecars and the pod overhead.
+func PodRequests(pod *corev1.Pod) corev1.ResourceList {
+	return resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+}
+
+// NodeResources returns every resource that any of the nodes offers to pods sorted by name.
+func NodeResources(nodes ...*corev1.Node) []Resource {
+	var names []corev1.ResourceName
+	for _, node := range nodes {
+		for name, quantity := range node.Status.Allocatable {
+			if !quantity.IsZero() && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	resources := make([]Resource, 0, len(names))
+	for _, name := range names {
+		resources = append(resources, NewResource(name))
+	}
+	return resources
+}
+
+// InsufficientResources returns the names of the resources that the node does not offer enough of for the pod.
+func InsufficientResources(node *corev1.Node, pod *corev1.Pod) []corev1.ResourceName {
+	var insufficient []corev1.ResourceName
+	for name, quantity := range PodRequests(pod) {
+		if quantity.IsZero() {
+			continue
+		}
+		if NewResource(name).Allocatable(node) < quantity.MilliValue() {
+			insufficient = append(insufficient, name)
+		}
+	}
+	slices.Sort(insufficient)
+	return insufficient
+}
+
+// WasteRatios returns the share of every resource of the node that stays unused by the pods assigned to it. Resources
+// that the node does not offer are skipped.
+func WasteRatios(node *corev1.Node, pods []corev1.Pod, resources []Resource) map[corev1.ResourceName]float64 {
+	return TotalWasteRatios([]*corev1.Node{node}, pods, resources)
+}
+
+// TotalWasteRatios returns the share of every resource of all given nodes together that stays unused by the pods
+// assigned to them. Resources that none of the nodes offers are skipped.
+func TotalWasteRatios(nodes []*corev1.Node, pods []corev1.Pod, resources []Resource) map[corev1.ResourceName]float64 {
+	nodeNames := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		nodeNames[node.Name] = true
+	}
+	wasteRatios := make(map[corev1.ResourceName]float64, len(resources))
+	for _, res := range resources {
+		var allocatable int64
+		for _, node := range nodes {
+			allocatable += res.Allocatable(node)
+		}
+		if allocatable <= 0 {
+			continue
+		}
+		var used int64
+		for i := range pods {
+			if nodeNames[pods[i].Spec.NodeName] {
+				used += res.Request(&pods[i])
+			}
+		}
+		wasteRatios[res.Name()] = float64(allocatable-used) / float64(allocatable)
+	}
+	return wasteRatios
+}
+
+// EffectiveWeights returns the weight of every resource for scoring the given pods. Configured weights take
+// precedence. Cpu, memory and every resource requested by one of the pods default to 1, all others to 0.
+func EffectiveWeights(configured map[corev1.ResourceName]float64, pods []corev1.Pod) map[corev1.ResourceName]float64 {
+	weights := map[corev1.ResourceName]float64{
+		corev1.ResourceCPU:    1,
+		corev1.ResourceMemory: 1,
+	}
+	for i := range pods {
+		for name, quantity := range PodRequests(&pods[i]) {
+			if !quantity.IsZero() {
+				weights[name] = 1
+			}
+		}
+	}
+	for name, weight := range configured {
+		weights[name] = weight
+	}
+	return weights
+}
+
+// WeightedWaste returns the weighted mean of the waste ratios. Resources without a positive weight are ignored.
+func WeightedWaste(wasteRatios map[corev1.ResourceName]float64, weights map[corev1.ResourceName]float64) float64 {
+	var weightedSum, weightSum float64
+	for name, wasteRatio := range wasteRatios {
+		weight := weights[name]
+		if weight <= 0 {
+			continue
+		}
+		weightedSum += weight * wasteRatio
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return weightedSum / weightSum
+}
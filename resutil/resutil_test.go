@@ -0,0 +1,81 @@
+package resutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(allocatable corev1.ResourceList) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status:     corev1.NodeStatus{Allocatable: allocatable, Capacity: allocatable},
+	}
+}
+
+func TestPodRequestsIncludesInitContainersAndOverhead(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+		}}}},
+		Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("500m"),
+			corev1.ResourceEphemeralStorage: resource.MustParse("2Gi"),
+		}}}},
+		Overhead: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}}
+	requests := PodRequests(pod)
+	assert.Equal(t, int64(600), requests.Cpu().MilliValue())
+	assert.Equal(t, resource.MustParse("10Gi"), requests[corev1.ResourceEphemeralStorage])
+}
+
+func TestWasteRatiosCoverAllNodeResources(t *testing.T) {
+	node := newNode(corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("2"),
+		corev1.ResourceMemory:           resource.MustParse("4Gi"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("20Gi"),
+		corev1.ResourcePods:             resource.MustParse("4"),
+		"nvidia.com/gpu":                resource.MustParse("1"),
+	})
+	pod := corev1.Pod{Spec: corev1.PodSpec{NodeName: node.Name, Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("1"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("15Gi"),
+	}}}}}}
+	assert.Empty(t, InsufficientResources(node, &pod))
+
+	wasteRatios := WasteRatios(node, []corev1.Pod{pod}, NodeResources(node))
+	assert.Equal(t, map[corev1.ResourceName]float64{
+		corev1.ResourceCPU:              0.5,
+		corev1.ResourceMemory:           1,
+		corev1.ResourceEphemeralStorage: 0.25,
+		corev1.ResourcePods:             0.75,
+		"nvidia.com/gpu":                1,
+	}, wasteRatios)
+	cpuNode := newNode(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")})
+	assert.Len(t, NodeResources(cpuNode), 1)
+	assert.Equal(t, NodeResources(node), NodeResources(cpuNode, node))
+
+	weights := EffectiveWeights(map[corev1.ResourceName]float64{corev1.ResourceMemory: 0}, []corev1.Pod{pod})
+	assert.Equal(t, map[corev1.ResourceName]float64{
+		corev1.ResourceCPU:              1,
+		corev1.ResourceMemory:           0,
+		corev1.ResourceEphemeralStorage: 1,
+	}, weights)
+	assert.InDelta(t, 0.375, WeightedWaste(wasteRatios, weights), 1e-9)
+}
+
+func TestInsufficientResources(t *testing.T) {
+	node := newNode(corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("2"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("20Gi"),
+	})
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("1"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("30Gi"),
+		"nvidia.com/gpu":                resource.MustParse("1"),
+	}}}}}}
+	assert.Equal(t, []corev1.ResourceName{corev1.ResourceEphemeralStorage, "nvidia.com/gpu"}, InsufficientResources(node, pod))
+}
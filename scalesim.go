@@ -3,6 +3,7 @@ package scalesim
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -17,25 +18,46 @@ import (
 
 	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 )
 
+// ErrReferenceNodeNotFound is returned by VirtualClusterAccess.GetReferenceNode for instance types without a reference
+// node.
+var ErrReferenceNodeNotFound = errors.New("reference node not found")
+
 // Engine is the primary simulation driver facade of the scaling simulator. Since Engine register routes for driving simulation scenarios it extends http.Handler
 type Engine interface {
 	http.Handler
 	VirtualClusterAccess() VirtualClusterAccess
 	ShootAccess(shootName string) ShootAccess
 	SyncVirtualNodesWithShoot(ctx context.Context, shootName string) error
+	// SyncPriorityClassesWithShoot creates or updates the PriorityClasses of the shoot in the virtual cluster.
+	SyncPriorityClassesWithShoot(ctx context.Context, shootName string) error
+	// SyncScheduledPodsWithShoot creates the scheduled pods of the shoot bound to their nodes in the virtual cluster
+	// and lifts the scheduling restriction of the existing nodes that they are bound to. The nodes have to be synced
+	// before.
+	SyncScheduledPodsWithShoot(ctx context.Context, shootName string) error
+	// SyncWorkloadWithShoot syncs the nodes of the shoot together with its PriorityClasses, namespaces, StorageClasses,
+	// PersistentVolumes, PersistentVolumeClaims, PodDisruptionBudgets and scheduled pods, so that the capacity of the
+	// existing nodes is used before new nodes are recommended.
+	SyncWorkloadWithShoot(ctx context.Context, shootName string) error
 	ScaleWorkerPoolsTillMaxOrNoUnscheduledPods(ctx context.Context, scenarioName string, since time.Time, shoot *gardencore.Shoot, w http.ResponseWriter) (int, error)
 	ScaleAllWorkerPoolsTillMax(ctx context.Context, scenarioName string, shoot *gardencore.Shoot, w http.ResponseWriter) (int, error)
 	ScaleWorkerPoolsTillNumZonesMultPoolsMax(ctx context.Context, scenarioName string, shoot *gardencore.Shoot, w http.ResponseWriter) (int, error)
 	ScaleWorkerPoolTillMax(ctx context.Context, scenarioName string, pool *gardencore.Worker, w http.ResponseWriter) (int, error)
+	// Shutdown stops the watch of a shoot if one is running.
+	Shutdown()
 }
 
 // VirtualClusterAccess represents access to the virtualcluster cluster managed by the simulator that shadows the real cluster
 type VirtualClusterAccess interface {
-	// KubeConfigPath gets path to the kubeconfig.yaml file that can be used by kubectl to connect to this vitual cluster
+	// KubeConfigPath gets path to the kubeconfig.yaml file that can be used by kubectl to connect to this vitual cluster.
+	// It is empty for an in-memory virtual cluster which cannot be reached by kubectl.
 	KubeConfigPath() string
 
 	// AddNodesAndUpdateLabels adds the given slice of k8s Nodes to the virtual cluster
@@ -59,6 +81,10 @@ type VirtualClusterAccess interface {
 	// CreatePods creates the given slice of k8s Pods in the virtual cluster
 	CreatePods(context.Context, string, ...corev1.Pod) error
 
+	// SchedulePods creates the given slice of k8s Pods in the virtual cluster like CreatePods and blocks until the
+	// scheduler has either bound each pod or found it unschedulable. Results are returned in the order of creation.
+	SchedulePods(ctx context.Context, podOrder string, pods ...corev1.Pod) ([]PodSchedulingResult, error)
+
 	// AddPods adds pods in the virtual cluster
 	AddPods(context.Context, ...corev1.Pod) error
 
@@ -70,6 +96,9 @@ type VirtualClusterAccess interface {
 	// ApplyK8sObject applies all Objects into the virtual cluster
 	ApplyK8sObject(context.Context, ...runtime.Object) error
 
+	// DeleteK8sObject deletes all Objects from the virtual cluster. Objects that do not exist are ignored.
+	DeleteK8sObject(context.Context, ...runtime.Object) error
+
 	// ClearAll clears all k8s objects from the virtual cluster.
 	ClearAll(ctx context.Context) error
 
@@ -113,42 +142,93 @@ type VirtualClusterAccess interface {
 	ListPodsMatchingLabels(ctx context.Context, labels map[string]string) ([]corev1.Pod, error)
 	ListPodsMatchingPodNames(ctx context.Context, namespace string, podNames []string) ([]corev1.Pod, error)
 
+	// AddPriorityClasses creates the given PriorityClasses in the virtual cluster or updates them if they exist.
+	AddPriorityClasses(ctx context.Context, priorityClasses ...*schedulingv1.PriorityClass) error
+
+	// ListPriorityClasses lists all PriorityClasses of the virtual cluster.
+	ListPriorityClasses(ctx context.Context) ([]schedulingv1.PriorityClass, error)
+
+	// ListPodDisruptionBudgets lists the PodDisruptionBudgets from all namespaces.
+	ListPodDisruptionBudgets(ctx context.Context) ([]policyv1.PodDisruptionBudget, error)
+
+	// GetReferenceNode returns the existing node that new nodes of the instance type are modelled on. The error wraps
+	// ErrReferenceNodeNotFound if there is none.
 	GetReferenceNode(instanceType string) (*corev1.Node, error)
 	InitializeReferenceNodes(ctx context.Context) error
+
+	// Fork returns an independent in-memory copy of the nodes, pods and reference nodes of the virtual cluster with its
+	// own scheduler. Changes to the fork do not affect the virtual cluster. The fork must be shut down once it is no
+	// longer needed.
+	Fork(ctx context.Context) (VirtualClusterAccess, error)
+
+	// Merge creates the nodes and bound pods of the given fork that are not present in the virtual cluster.
+	Merge(ctx context.Context, fork VirtualClusterAccess) error
 }
 
-// ShootAccess is a facade to the real-world shoot data and real shoot cluster
+// ShootAccess is a facade to the real-world shoot data and real shoot cluster. Every call is aborted once the given
+// context is done, implementations additionally bound each call with their own timeout.
 type ShootAccess interface {
 	// ProjectName returns the project name that the shoot belongs to
 	ProjectName() string
 
 	// GetShootObj returns the shoot object describing the shoot cluster
-	GetShootObj() (*gardencore.Shoot, error)
+	GetShootObj(ctx context.Context) (*gardencore.Shoot, error)
 
 	// GetNodes returns slice of nodes of the shoot cluster
-	GetNodes() ([]*corev1.Node, error)
+	GetNodes(ctx context.Context) ([]*corev1.Node, error)
 
 	// GetUnscheduledPods returns slice of unscheduled pods of the shoot cluster
-	GetUnscheduledPods() ([]corev1.Pod, error)
+	GetUnscheduledPods(ctx context.Context) ([]corev1.Pod, error)
+
+	// GetScheduledPods returns slice of the pods of all namespaces of the shoot cluster that are bound to a node and
+	// not terminated
+	GetScheduledPods(ctx context.Context) ([]corev1.Pod, error)
+
+	GetDSPods(ctx context.Context) ([]corev1.Pod, error)
+
+	// GetConfigMap returns the given config map of the shoot cluster or nil if it does not exist
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+
+	// GetPriorityClasses returns slice of PriorityClasses of the shoot cluster
+	GetPriorityClasses(ctx context.Context) ([]*schedulingv1.PriorityClass, error)
+
+	// GetNamespaces returns slice of namespaces of the shoot cluster
+	GetNamespaces(ctx context.Context) ([]*corev1.Namespace, error)
+
+	// GetStorageClasses returns slice of StorageClasses of the shoot cluster
+	GetStorageClasses(ctx context.Context) ([]*storagev1.StorageClass, error)
 
-	GetDSPods() ([]corev1.Pod, error)
+	// GetPersistentVolumes returns slice of PersistentVolumes of the shoot cluster
+	GetPersistentVolumes(ctx context.Context) ([]*corev1.PersistentVolume, error)
+
+	// GetPersistentVolumeClaims returns slice of PersistentVolumeClaims of all namespaces of the shoot cluster
+	GetPersistentVolumeClaims(ctx context.Context) ([]*corev1.PersistentVolumeClaim, error)
+
+	// GetPodDisruptionBudgets returns slice of PodDisruptionBudgets of all namespaces of the shoot cluster
+	GetPodDisruptionBudgets(ctx context.Context) ([]*policyv1.PodDisruptionBudget, error)
 
 	// GetMachineDeployments returns slice of machine deployments of the shoot cluster
-	GetMachineDeployments() ([]*machinev1alpha1.MachineDeployment, error)
+	GetMachineDeployments(ctx context.Context) ([]*machinev1alpha1.MachineDeployment, error)
 
 	// ScaleMachineDeployment scales the given machine deployment to the given number of replicas
-	ScaleMachineDeployment(machineDeploymentName string, replicas int32) error
+	ScaleMachineDeployment(ctx context.Context, machineDeploymentName string, replicas int32) error
 
 	// CreatePods creates the given slice of k8s Pods in the shoot cluster
-	CreatePods(filePath string, replicas int) error
+	CreatePods(ctx context.Context, filePath string, replicas int) error
 
-	TaintNodes() error
+	TaintNodes(ctx context.Context) error
 
-	UntaintNodes() error
+	UntaintNodes(ctx context.Context) error
 
-	DeleteAllPods() error
+	DeleteAllPods(ctx context.Context) error
+
+	CleanUp(ctx context.Context) error
+}
 
-	CleanUp() error
+// ShootRESTConfigAccess is implemented by the ShootAccess implementations that talk to the shoot cluster through
+// clients. The rest config allows to watch the shoot and may expire, so it has to be requested again for long watches.
+type ShootRESTConfigAccess interface {
+	GetShootRESTConfig(ctx context.Context) (*rest.Config, error)
 }
 
 // Scenario represents a scaling simulation scenario. Each scenario is invocable by an HTTP endpoint and hence extends http.Handler
@@ -163,11 +243,26 @@ type Scenario interface {
 
 // NodePool describes a worker pool in the shoot.
 type NodePool struct {
-	Name        string
+	Name string
+	// Zones are the zones of the node pool that can still be scaled up.
 	Zones       []string
 	Max         int32
 	Current     int32
 	MachineType string
+	// Priority is the priority of the node pool, higher values are preferred by the priority scoring strategy.
+	Priority int
+	// ZoneLimits are the limits of the machine deployment of the node pool in each zone.
+	ZoneLimits map[string]ZoneLimits
+	// ZoneSizes is the number of nodes of the node pool in each zone that count against the zone limits.
+	ZoneSizes map[string]int32
+}
+
+// ZoneLimits are the limits of the machine deployment of a worker pool in one zone.
+type ZoneLimits struct {
+	Min int32
+	Max int32
+	// MaxSurge is the number of machines that can be created above the desired number during a rolling update.
+	MaxSurge int32
 }
 
 type NodePodAssignment struct {
@@ -203,10 +298,12 @@ func (s ScalerRecommendations) String() string {
 }
 
 type NodeRunResult struct {
-	NodeName         string
-	Pool             *gardencore.Worker
-	MemoryWasteRatio float64
-	CPUWasteRatio    float64
+	NodeName string
+	Pool     *gardencore.Worker
+	// WasteRatios holds the unused share of every resource of the node.
+	WasteRatios map[corev1.ResourceName]float64
+	// WasteRatio is the weighted waste over all resources.
+	WasteRatio       float64
 	UnscheduledRatio float64
 	CostRatio        float64
 	CumulativeScore  float64
@@ -216,7 +313,40 @@ type NodeRunResult struct {
 }
 
 func (n NodeRunResult) String() string {
-	return fmt.Sprintf("(Worker: %s, MemoryWasteRatio: %.4f, CPUWasteRatio: %.4f, UnscheduledRatio: %.4f, CostRatio: %.4f, CumulativeScore: %.4f, NumAssignedPodsToNode: %d, NumAssignedPodsTotal: %d)", n.Pool.Name, n.MemoryWasteRatio, n.CPUWasteRatio, n.UnscheduledRatio, n.CostRatio, n.CumulativeScore, n.NumAssignedPodsToNode, n.NumAssignedPodsTotal)
+	return fmt.Sprintf("(Worker: %s, WasteRatio: %.4f, WasteRatios: %v, UnscheduledRatio: %.4f, CostRatio: %.4f, CumulativeScore: %.4f, NumAssignedPodsToNode: %d, NumAssignedPodsTotal: %d)", n.Pool.Name, n.WasteRatio, n.WasteRatios, n.UnscheduledRatio, n.CostRatio, n.CumulativeScore, n.NumAssignedPodsToNode, n.NumAssignedPodsTotal)
+}
+
+// PodSchedulingResult is the outcome of a single scheduling attempt of a pod in the virtual cluster.
+type PodSchedulingResult struct {
+	Pod types.NamespacedName `json:"pod"`
+	// NodeName is the name of the node that the pod was bound to. It is empty if the pod is unschedulable.
+	NodeName string `json:"nodeName,omitempty"`
+	// PreFilterFailure is set when a pre-filter plugin rejected the pod before any node was evaluated.
+	PreFilterFailure *PluginStatus `json:"preFilterFailure,omitempty"`
+	// FilterFailures holds the status of the filter plugin that rejected the pod, keyed by node name.
+	FilterFailures map[string]PluginStatus `json:"filterFailures,omitempty"`
+	// NodeScores holds the scores of all feasible nodes.
+	NodeScores []NodeScore `json:"nodeScores,omitempty"`
+	// Message summarizes the result in the same format as the message of the Scheduled or FailedScheduling event.
+	Message string `json:"message"`
+}
+
+// IsScheduled returns true if the pod was bound to a node.
+func (p PodSchedulingResult) IsScheduled() bool {
+	return p.NodeName != ""
+}
+
+// PluginStatus is the status returned by a scheduler plugin that rejected a pod.
+type PluginStatus struct {
+	Plugin  string   `json:"plugin"`
+	Reasons []string `json:"reasons"`
+}
+
+// NodeScore is the score computed by the scheduler score plugins for a feasible node.
+type NodeScore struct {
+	NodeName     string           `json:"nodeName"`
+	TotalScore   int64            `json:"totalScore"`
+	PluginScores map[string]int64 `json:"pluginScores"`
 }
 
 type AllPricing struct {
@@ -261,6 +391,8 @@ func (ns NodeRunResults) GetTotalAssignedPods() int {
 type StrategyWeights struct {
 	LeastWaste float64
 	LeastCost  float64
+	// ResourceWeights weights the waste of individual resources, see resutil.EffectiveWeights for the defaults.
+	ResourceWeights map[corev1.ResourceName]float64
 }
 
 type Recommendations map[string]*Recommendation
@@ -1,6 +1,7 @@
 package scaleutil
 
 import (
+	"context"
 	"fmt"
 	scalesim "github.com/elankath/scaler-simulator"
 	"github.com/elankath/scaler-simulator/webutil"
@@ -10,9 +11,9 @@ import (
 )
 
 // ParseRecommendationsAndScaleUp parses the recommendations and scales up the actual cluster
-func ParseRecommendationsAndScaleUp(s scalesim.ShootAccess, recommendations scalesim.ScalerRecommendations, w http.ResponseWriter) error {
+func ParseRecommendationsAndScaleUp(ctx context.Context, s scalesim.ShootAccess, recommendations scalesim.ScalerRecommendations, w http.ResponseWriter) error {
 	slog.Info("Parsing recommendations and scaling up", "recommendations", recommendations)
-	mcds, err := s.GetMachineDeployments()
+	mcds, err := s.GetMachineDeployments(ctx)
 	if err != nil {
 		slog.Error("Error getting machine deployments", "error", err)
 		return err
@@ -27,7 +28,7 @@ func ParseRecommendationsAndScaleUp(s scalesim.ShootAccess, recommendations scal
 				desiredReplicas := mcd.Spec.Replicas + (int32)(increment)
 				slog.Info("Scaling up", "mcd", mcd.Name, "zone", zone, "replicas", desiredReplicas, "increment", increment)
 				webutil.Log(w, fmt.Sprintf("Scaling up %s mcd to %d replicas", mcd.Name, desiredReplicas))
-				err := s.ScaleMachineDeployment(mcd.Name, desiredReplicas)
+				err := s.ScaleMachineDeployment(ctx, mcd.Name, desiredReplicas)
 				if err != nil {
 					slog.Error("Error scaling up", "mcd", mcd.Name, "zone", zone, "replicas", desiredReplicas, "error", err)
 					return err
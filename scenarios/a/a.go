@@ -30,7 +30,7 @@ func New(engine scalesim.Engine) scalesim.Scenario {
 
 func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	webutil.Log(w, "Cleaning up real shoot for scenario: "+s.Name()+"...")
-	err := s.engine.ShootAccess(shootName).CleanUp()
+	err := s.engine.ShootAccess(shootName).CleanUp(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 	} else {
@@ -40,7 +40,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	webutil.Log(w, "Commencing scenario: "+s.Name()+"...")
 
 	webutil.Log(w, "Tainting existing nodes in shoot: "+shootName+"...")
-	err = s.engine.ShootAccess(shootName).TaintNodes()
+	err = s.engine.ShootAccess(shootName).TaintNodes(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
@@ -52,7 +52,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	workingDir, _ := os.Getwd()
 	absolutePath := workingDir + "/" + podSpecPath
 	webutil.Log(w, fmt.Sprintf("Applying %d replicas of pod spec: %s...", podCount, podSpecPath))
-	err = s.engine.ShootAccess(shootName).CreatePods(absolutePath, podCount)
+	err = s.engine.ShootAccess(shootName).CreatePods(r.Context(), absolutePath, podCount)
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
@@ -71,7 +71,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	webutil.Log(w, fmt.Sprintf("Getting shoot object for shoot: %s using kubectl ...", shootName))
-	shoot, err := s.engine.ShootAccess(shootName).GetShootObj()
+	shoot, err := s.engine.ShootAccess(shootName).GetShootObj(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
@@ -85,7 +85,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	webutil.Log(w, fmt.Sprintf("Created %d total virtual nodes", numCreatedNodes))
 
-	dsPods, err := s.engine.ShootAccess(shootName).GetDSPods()
+	dsPods, err := s.engine.ShootAccess(shootName).GetDSPods(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
@@ -96,7 +96,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	unscheduledPods, err := s.engine.ShootAccess(shootName).GetUnscheduledPods()
+	unscheduledPods, err := s.engine.ShootAccess(shootName).GetUnscheduledPods(r.Context())
 	if err != nil {
 		simutil.LogError(w, s.Name(), err)
 		return
@@ -143,7 +143,7 @@ func (s *scenarioA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = scaleutil.ParseRecommendationsAndScaleUp(s.engine.ShootAccess(shootName), recommendation, w)
+	err = scaleutil.ParseRecommendationsAndScaleUp(r.Context(), s.engine.ShootAccess(shootName), recommendation, w)
 	if err != nil {
 		simutil.LogError(w, s.Name(), err)
 		return
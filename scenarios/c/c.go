@@ -41,7 +41,7 @@ func (s *scenarioC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		webutil.InternalError(w, err)
 		return
 	}
-	shoot, err := s.engine.ShootAccess(shootName).GetShootObj()
+	shoot, err := s.engine.ShootAccess(shootName).GetShootObj(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
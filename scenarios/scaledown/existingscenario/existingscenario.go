@@ -0,0 +1,51 @@
+package existingscenario
+
+import (
+	"net/http"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/scenarios/scaledown"
+	"github.com/elankath/scaler-simulator/webutil"
+)
+
+var (
+	shootName    = "scenario-a"
+	scenarioName = "existing"
+)
+
+type existingScaleDown struct {
+	engine scalesim.Engine
+}
+
+func New(engine scalesim.Engine) scalesim.Scenario {
+	return &existingScaleDown{
+		engine: engine,
+	}
+}
+
+// ServeHTTP recommends which nodes of the shoot given by the query param shoot can be removed. The nodes are synced
+// with all their scheduled pods and no further nodes or pods are added.
+func (s *existingScaleDown) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webutil.Log(w, "Commencing scenario: "+s.Name()+"...")
+	options, err := scaledown.ScaleDownOptionsFromRequest(r)
+	if err != nil {
+		webutil.BadRequest(w, err)
+		return
+	}
+	options.IncludeExistingNodes = true
+	scaledown.NewScenarioRunner(s.engine, webutil.GetStringQueryParam(r, "shoot", shootName), scenarioName, nil, options).Run(r.Context(), w)
+}
+
+var _ scalesim.Scenario = (*existingScaleDown)(nil)
+
+func (s *existingScaleDown) Description() string {
+	return "Scale down the existing nodes of a shoot with all their scheduled pods"
+}
+
+func (s *existingScaleDown) ShootName() string {
+	return shootName
+}
+
+func (s *existingScaleDown) Name() string {
+	return scenarioName
+}
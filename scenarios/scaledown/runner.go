@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"slices"
+	"strconv"
 	"time"
 
 	scalesim "github.com/elankath/scaler-simulator"
@@ -21,34 +22,70 @@ type ScenarioRunner struct {
 	shootName    string
 	scenarioName string
 	podRequests  map[string]int
+	options      recommender.ScaleDownOptions
 }
 
 type SetupScenarioFunc func(ctx context.Context) error
 
-func NewScenarioRunner(engine scalesim.Engine, shootName, scenarioName string, podRequests map[string]int) *ScenarioRunner {
+func NewScenarioRunner(engine scalesim.Engine, shootName, scenarioName string, podRequests map[string]int, options recommender.ScaleDownOptions) *ScenarioRunner {
 	return &ScenarioRunner{
 		engine:       engine,
 		shootName:    shootName,
 		scenarioName: scenarioName,
 		podRequests:  podRequests,
+		options:      options,
 	}
 }
 
+// ScaleDownOptionsFromRequest returns the default scale-down options overridden by the query params
+// skipNodesWithSystemPods, skipNodesWithLocalStorage, skipNodesWithUnreplicatedPods, respectPDBs,
+// utilizationThreshold, ignoreDaemonSetsUtilization, unneededTime and existingNodes.
+func ScaleDownOptionsFromRequest(r *http.Request) (recommender.ScaleDownOptions, error) {
+	opts := recommender.DefaultScaleDownOptions()
+	boolParam := func(name string, defVal bool) bool {
+		return webutil.GetStringQueryParam(r, name, strconv.FormatBool(defVal)) == "true"
+	}
+	opts.Rules.SkipNodesWithSystemPods = boolParam("skipNodesWithSystemPods", opts.Rules.SkipNodesWithSystemPods)
+	opts.Rules.SkipNodesWithLocalStorage = boolParam("skipNodesWithLocalStorage", opts.Rules.SkipNodesWithLocalStorage)
+	opts.Rules.SkipNodesWithUnreplicatedPods = boolParam("skipNodesWithUnreplicatedPods", opts.Rules.SkipNodesWithUnreplicatedPods)
+	opts.Rules.RespectPodDisruptionBudgets = boolParam("respectPDBs", opts.Rules.RespectPodDisruptionBudgets)
+	opts.IncludeExistingNodes = boolParam("existingNodes", opts.IncludeExistingNodes)
+	opts.IgnoreDaemonSetsUtilization = boolParam("ignoreDaemonSetsUtilization", opts.IgnoreDaemonSetsUtilization)
+	var err error
+	if opts.UtilizationThreshold, err = webutil.GetFloatQueryParam(r, "utilizationThreshold", opts.UtilizationThreshold); err != nil {
+		return opts, fmt.Errorf("invalid utilizationThreshold: %w", err)
+	}
+	if opts.UnneededTime, err = time.ParseDuration(webutil.GetStringQueryParam(r, "unneededTime", opts.UnneededTime.String())); err != nil {
+		return opts, fmt.Errorf("invalid unneededTime: %w", err)
+	}
+	return opts, nil
+}
+
 func (s ScenarioRunner) Run(ctx context.Context, w http.ResponseWriter) {
 	if err := s.resetVirtualCluster(ctx, s.engine, s.shootName); err != nil {
 		webutil.InternalError(w, err)
 		return
 	}
-	shoot, err := s.engine.ShootAccess(s.shootName).GetShootObj()
-	if err != nil {
-		webutil.InternalError(w, err)
-		return
+	if s.options.IncludeExistingNodes {
+		if err := s.syncWorkload(ctx, w); err != nil {
+			webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
+			slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
+			webutil.InternalError(w, err)
+			return
+		}
 	}
-	if err = s.createNodesInVirtualCluster(ctx, w, shoot); err != nil {
-		webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
-		webutil.InternalError(w, err)
-		slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
-		return
+	if len(s.podRequests) > 0 {
+		shoot, err := s.engine.ShootAccess(s.shootName).GetShootObj(ctx)
+		if err != nil {
+			webutil.InternalError(w, err)
+			return
+		}
+		if err = s.createNodesInVirtualCluster(ctx, w, shoot); err != nil {
+			webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
+			webutil.InternalError(w, err)
+			slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
+			return
+		}
 	}
 
 	//if err := setupFunc(ctx); err != nil {
@@ -58,11 +95,13 @@ func (s ScenarioRunner) Run(ctx context.Context, w http.ResponseWriter) {
 	//	return
 	//}
 
-	if err := s.deployPods(ctx, w, s.podRequests); err != nil {
-		webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
-		slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
-		webutil.InternalError(w, err)
-		return
+	if len(s.podRequests) > 0 {
+		if err := s.deployPods(ctx, w, s.podRequests); err != nil {
+			webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
+			slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
+			webutil.InternalError(w, err)
+			return
+		}
 	}
 	originalNodePodAssignments := s.printAndGetNodePodAssignments(ctx, w)
 
@@ -72,7 +111,8 @@ func (s ScenarioRunner) Run(ctx context.Context, w http.ResponseWriter) {
 		return
 	}
 
-	scaleDownRecommendation, err := recommender.ScaleDownOrderedByDescendingCost(ctx, s.engine.VirtualClusterAccess(), w, nodes)
+	webutil.Log(w, fmt.Sprintf("Scale-down options: %+v", s.options))
+	removals, blockedNodes, err := recommender.ScaleDownOrderedByDescendingCost(ctx, s.engine.VirtualClusterAccess(), w, nodes, s.options)
 	if err != nil {
 		webutil.Log(w, "Execution of scenario: "+s.scenarioName+" completed with error: "+err.Error())
 		slog.Error("Execution of scenario: "+s.scenarioName+" ran into error", "error", err)
@@ -81,11 +121,27 @@ func (s ScenarioRunner) Run(ctx context.Context, w http.ResponseWriter) {
 	originalNodeNames := lo.Map(originalNodePodAssignments, func(npa scalesim.NodePodAssignment, _ int) string {
 		return npa.NodeName
 	})
+	for _, n := range nodes {
+		if simutil.IsExistingNode(&n) && s.options.IncludeExistingNodes && !slices.Contains(originalNodeNames, n.Name) {
+			originalNodeNames = append(originalNodeNames, n.Name)
+		}
+	}
 	webutil.Log(w, fmt.Sprintf("Initial Nodes in the cluster: %s", originalNodeNames))
-	scaleDownRecommendation = lo.Filter(scaleDownRecommendation, func(nodeName string, _ int) bool {
-		return slices.Contains(originalNodeNames, nodeName)
+	removals = lo.Filter(removals, func(removal recommender.NodeRemoval, _ int) bool {
+		return slices.Contains(originalNodeNames, removal.Name)
+	})
+	scaleDownRecommendation := lo.Map(removals, func(removal recommender.NodeRemoval, _ int) string {
+		return removal.Name
 	})
 	webutil.Log(w, fmt.Sprintf("Recommendation for Scale-Down: %s", scaleDownRecommendation))
+	for _, removal := range removals {
+		webutil.Log(w, fmt.Sprintf("Node %s with utilization %.2f is removed after %s", removal.Name, removal.Utilization, removal.After))
+	}
+	for _, blockedNode := range blockedNodes {
+		if slices.Contains(originalNodeNames, blockedNode.Name) {
+			webutil.Log(w, fmt.Sprintf("Not removable: %s", blockedNode))
+		}
+	}
 	webutil.Log(w, "Scenario-End: "+s.scenarioName)
 }
 
@@ -121,12 +177,22 @@ func (s ScenarioRunner) resetVirtualCluster(ctx context.Context, engine scalesim
 	if err := engine.VirtualClusterAccess().ClearAll(ctx); err != nil {
 		return err
 	}
+	if s.options.IncludeExistingNodes {
+		return nil
+	}
 	if err := engine.SyncVirtualNodesWithShoot(ctx, s.shootName); err != nil {
 		return err
 	}
 	return nil
 }
 
+// syncWorkload syncs the nodes of the shoot with their scheduled pods, PodDisruptionBudgets and the other objects they
+// depend on, so that the nodes can be evaluated as removal candidates.
+func (s ScenarioRunner) syncWorkload(ctx context.Context, w http.ResponseWriter) error {
+	webutil.Log(w, "Syncing nodes and workload of shoot: "+s.shootName)
+	return s.engine.SyncWorkloadWithShoot(ctx, s.shootName)
+}
+
 func (s ScenarioRunner) createNodesInVirtualCluster(ctx context.Context, w http.ResponseWriter, shoot *v1beta1.Shoot) error {
 	webutil.Log(w, "Scenario-Start: Scaling worker pools in virtual cluster till worker pool max...")
 	numCreatedNodes, err := s.engine.ScaleAllWorkerPoolsTillMax(ctx, s.scenarioName, shoot, w)
@@ -38,7 +38,12 @@ func (s *tscScaleDown) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		smallPodPath: smallCount,
 		largePodPath: largeCount,
 	}
-	scaledown.NewScenarioRunner(s.engine, shootName, scenarioName, podRequests).Run(r.Context(), w)
+	options, err := scaledown.ScaleDownOptionsFromRequest(r)
+	if err != nil {
+		webutil.BadRequest(w, err)
+		return
+	}
+	scaledown.NewScenarioRunner(s.engine, shootName, scenarioName, podRequests, options).Run(r.Context(), w)
 }
 
 var _ scalesim.Scenario = (*tscScaleDown)(nil)
@@ -42,7 +42,7 @@ package score3
 //		webutil.InternalError(w, err)
 //		return
 //	}
-//	shoot, err := s.engine.ShootAccess(shootName).GetShootObj()
+//	shoot, err := s.engine.ShootAccess(shootName).GetShootObj(r.Context())
 //	if err != nil {
 //		webutil.InternalError(w, err)
 //		return
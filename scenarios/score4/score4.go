@@ -7,6 +7,7 @@ import (
 
 	scalesim "github.com/elankath/scaler-simulator"
 	"github.com/elankath/scaler-simulator/nodescorer"
+	"github.com/elankath/scaler-simulator/scorer"
 	"github.com/elankath/scaler-simulator/simutil"
 	"github.com/elankath/scaler-simulator/virtualcluster"
 	"github.com/elankath/scaler-simulator/webutil"
@@ -53,12 +54,22 @@ func (s *scenarioscore4) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	largeCount := webutil.GetIntQueryParam(r, "large", 2)
 	leastWasteWeight, err := webutil.GetFloatQueryParam(r, "leastWaste", 1.0)
 	if err != nil {
-		webutil.InternalError(w, err)
+		webutil.BadRequest(w, err)
 		return
 	}
 	leastCostWeight, err := webutil.GetFloatQueryParam(r, "leastCost", 1.0)
 	if err != nil {
-		webutil.InternalError(w, err)
+		webutil.BadRequest(w, err)
+		return
+	}
+	resourceWeights, err := webutil.GetResourceFactorsQueryParam(r, "resourceWeights")
+	if err != nil {
+		webutil.BadRequest(w, err)
+		return
+	}
+	nodeScorer, err := scorer.New(webutil.GetStringQueryParam(r, "strategy", scorer.CompositeStrategy), leastWasteWeight, leastCostWeight)
+	if err != nil {
+		webutil.BadRequest(w, err)
 		return
 	}
 
@@ -117,11 +128,12 @@ func (s *scenarioscore4) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	recommender := nodescorer.NewRecommender(s.engine, scenarioName, shootName, podOrder, scalesim.StrategyWeights{
-		LeastWaste: leastWasteWeight,
-		LeastCost:  leastCostWeight,
-	}, w)
+		LeastWaste:      leastWasteWeight,
+		LeastCost:       leastCostWeight,
+		ResourceWeights: resourceWeights,
+	}, nodeScorer, w)
 
-	shoot, err := s.engine.ShootAccess(shootName).GetShootObj()
+	shoot, err := s.engine.ShootAccess(shootName).GetShootObj(r.Context())
 	if err != nil {
 		webutil.InternalError(w, err)
 		return
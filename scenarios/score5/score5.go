@@ -1,10 +1,9 @@
 package score5
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/elankath/scaler-simulator/pricing"
 	"github.com/elankath/scaler-simulator/recommender"
@@ -12,6 +11,7 @@ import (
 	"github.com/samber/lo"
 
 	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/scorer"
 	"github.com/elankath/scaler-simulator/simutil"
 	"github.com/elankath/scaler-simulator/virtualcluster"
 	"github.com/elankath/scaler-simulator/webutil"
@@ -47,28 +47,54 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		webutil.InternalError(w, err)
 		return
 	}
-	webutil.Log(w, fmt.Sprintf("Synchronizing virtual nodes with nodes of shoot: %s ...", shootName))
-	err = s.engine.SyncVirtualNodesWithShoot(r.Context(), shootName)
-	if err != nil {
-		webutil.InternalError(w, err)
-		return
+	if webutil.GetStringQueryParam(r, "syncWorkload", "false") == "true" {
+		webutil.Log(w, fmt.Sprintf("Synchronizing virtual cluster with nodes and workload of shoot: %s ...", shootName))
+		if err = s.engine.SyncWorkloadWithShoot(r.Context(), shootName); err != nil {
+			webutil.InternalError(w, err)
+			return
+		}
+	} else {
+		webutil.Log(w, fmt.Sprintf("Synchronizing virtual nodes with nodes of shoot: %s ...", shootName))
+		err = s.engine.SyncVirtualNodesWithShoot(r.Context(), shootName)
+		if err != nil {
+			webutil.InternalError(w, err)
+			return
+		}
+
+		webutil.Log(w, fmt.Sprintf("Synchronizing virtual priority classes with priority classes of shoot: %s ...", shootName))
+		if err = s.engine.SyncPriorityClassesWithShoot(r.Context(), shootName); err != nil {
+			webutil.InternalError(w, err)
+			return
+		}
 	}
 
 	smallCount := webutil.GetIntQueryParam(r, "small", 10)
 	largeCount := webutil.GetIntQueryParam(r, "large", 2)
 	leastWasteWeight, err := webutil.GetFloatQueryParam(r, "leastWaste", 1.0)
 	if err != nil {
-		webutil.InternalError(w, err)
+		webutil.BadRequest(w, err)
 		return
 	}
 	leastCostWeight, err := webutil.GetFloatQueryParam(r, "leastCost", 1.0)
 	if err != nil {
-		webutil.InternalError(w, err)
+		webutil.BadRequest(w, err)
+		return
+	}
+	resourceWeights, err := webutil.GetResourceFactorsQueryParam(r, "resourceWeights")
+	if err != nil {
+		webutil.BadRequest(w, err)
+		return
+	}
+	nodeScorer, err := scorer.New(webutil.GetStringQueryParam(r, "strategy", scorer.CompositeStrategy), leastWasteWeight, leastCostWeight)
+	if err != nil {
+		webutil.BadRequest(w, err)
 		return
 	}
 
 	podOrder := webutil.GetStringQueryParam(r, "podOrder", "noorder")
 	withTSC := webutil.GetStringQueryParam(r, "withTSC", "false")
+	smallPriorityClass := webutil.GetStringQueryParam(r, "smallPriorityClass", "")
+	largePriorityClass := webutil.GetStringQueryParam(r, "largePriorityClass", "")
 
 	allPods := make([]corev1.Pod, 0, smallCount+largeCount)
 	smallPodLabels := map[string]string{
@@ -87,7 +113,7 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		matchingTSCLabels := map[string]string{
 			"foo": "bar",
 		}
-		smallPods, err := constructPodsWithTSC("small", virtualcluster.BinPackingSchedulerName, "", "5Gi", "100m", smallCount, &topologyKeyForZone, &maxSkew, matchingTSCLabels, smallPodLabels)
+		smallPods, err := constructPodsWithTSC("small", virtualcluster.BinPackingSchedulerName, "", smallPriorityClass, "5Gi", "100m", smallCount, &topologyKeyForZone, &maxSkew, matchingTSCLabels, smallPodLabels)
 		if err != nil {
 			webutil.InternalError(w, err)
 			return
@@ -96,7 +122,7 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		matchingTSCLabels = map[string]string{
 			"foo": "bar2",
 		}
-		largePods, err := constructPodsWithTSC("large", virtualcluster.BinPackingSchedulerName, "", "12Gi", "200m", largeCount, &topologyKeyForHostname, &maxSkew, matchingTSCLabels, largePodLabels)
+		largePods, err := constructPodsWithTSC("large", virtualcluster.BinPackingSchedulerName, "", largePriorityClass, "12Gi", "200m", largeCount, &topologyKeyForHostname, &maxSkew, matchingTSCLabels, largePodLabels)
 		if err != nil {
 			webutil.InternalError(w, err)
 			return
@@ -105,14 +131,14 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		allPods = append(allPods, largePods...)
 	} else {
 		delete(smallPodLabels, "foo")
-		smallPods, err := constructPodsWithoutTSC("small", virtualcluster.BinPackingSchedulerName, "", "3Gi", "100m", smallCount, smallPodLabels)
+		smallPods, err := constructPodsWithoutTSC("small", virtualcluster.BinPackingSchedulerName, "", smallPriorityClass, "3Gi", "100m", smallCount, smallPodLabels)
 		if err != nil {
 			webutil.InternalError(w, err)
 			return
 		}
 
 		delete(largePodLabels, "foo")
-		largePods, err := constructPodsWithoutTSC("large", virtualcluster.BinPackingSchedulerName, "", "12Gi", "200m", largeCount, largePodLabels)
+		largePods, err := constructPodsWithoutTSC("large", virtualcluster.BinPackingSchedulerName, "", largePriorityClass, "12Gi", "200m", largeCount, largePodLabels)
 		if err != nil {
 			webutil.InternalError(w, err)
 			return
@@ -126,7 +152,7 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	shoot, err := s.engine.ShootAccess(shootName).GetShootObj()
+	shoot, err := s.engine.ShootAccess(shootName).GetShootObj(r.Context())
 	if err != nil {
 		webutil.Log(w, "Execution of scenario: "+scenarioName+" completed with error: "+err.Error())
 		return
@@ -134,19 +160,49 @@ func (s *scenarioscore5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	instanceTypeCostRatios := computeCostRatiosForInstanceTypes(shoot.Spec.Provider.Workers)
 	reco := recommender.NewRecommender(s.engine, scenarioName, podOrder, shoot, instanceTypeCostRatios, recommender.StrategyWeights{
-		LeastWaste: leastWasteWeight,
-		LeastCost:  leastCostWeight,
-	}, w)
+		LeastWaste:      leastWasteWeight,
+		LeastCost:       leastCostWeight,
+		ResourceWeights: resourceWeights,
+	}, nodeScorer, w)
+	if webutil.GetStringQueryParam(r, "priorityMode", "false") == "true" {
+		if err = reco.EnablePriorityMode(r.Context()); err != nil {
+			webutil.Log(w, "Execution of scenario: "+scenarioName+" completed with error: "+err.Error())
+			return
+		}
+	}
 
-	startTime := time.Now()
+	if webutil.GetStringQueryParam(r, "balance", "false") == "true" {
+		reco.EnableBalancing()
+	}
+	if webutil.GetStringQueryParam(r, "daemonSets", "false") == "true" {
+		dsHeadroom, err := webutil.GetResourceFactorsQueryParam(r, "dsHeadroom")
+		if err != nil {
+			webutil.BadRequest(w, err)
+			return
+		}
+		if err = reco.EnableDaemonSetPods(r.Context(), dsHeadroom); err != nil {
+			webutil.Log(w, "Execution of scenario: "+scenarioName+" completed with error: "+err.Error())
+			return
+		}
+	}
+
+	if webutil.GetStringQueryParam(r, "preemption", "false") == "true" {
+		reco.EnablePreemption(int32(webutil.GetIntQueryParam(r, "expendablePodsPriorityCutoff", recommender.DefaultExpendablePodsPriorityCutoff)))
+	}
 
-	recommendation, err := reco.Run(context.Background(), allPods)
+	result, err := reco.RunWithResult(r.Context(), allPods)
+	if err != nil {
+		webutil.Log(w, "Execution of scenario: "+s.Name()+" completed with error: "+err.Error())
+	} else {
+		webutil.Log(w, fmt.Sprintf("Execution of scenario: %s completed in %f seconds", scenarioName, result.DurationSeconds))
+	}
+	webutil.Log(w, "Recommendation: "+result.String())
+	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		webutil.Log(w, "Execution of scenario: "+s.Name()+" completed with error: "+err.Error())
 		return
 	}
-	webutil.Log(w, fmt.Sprintf("Execution of scenario: %s completed in %f seconds", scenarioName, time.Since(startTime).Seconds()))
-	webutil.Log(w, fmt.Sprintf("Recommendation: %+v", recommendation))
+	webutil.Log(w, "Recommendation JSON: "+string(resultJSON))
 	webutil.Log(w, fmt.Sprintf("Scenario-%s Completed!", s.Name()))
 }
 
@@ -176,15 +232,15 @@ func computeCostRatiosForInstanceTypes(workerPools []v1beta1.Worker) map[string]
 	return instanceTypeCostRatios
 }
 
-func constructPodsWithTSC(namePrefix string, schedulerName string, nodeName string, memRequest, cpuRequest string, count int, topologyKey *string, maxSkew *int, matchingTSCLabels map[string]string, labels map[string]string) ([]corev1.Pod, error) {
-	return constructPods(namePrefix, schedulerName, nodeName, memRequest, cpuRequest, count, topologyKey, maxSkew, matchingTSCLabels, labels)
+func constructPodsWithTSC(namePrefix string, schedulerName string, nodeName string, priorityClassName string, memRequest, cpuRequest string, count int, topologyKey *string, maxSkew *int, matchingTSCLabels map[string]string, labels map[string]string) ([]corev1.Pod, error) {
+	return constructPods(namePrefix, schedulerName, nodeName, priorityClassName, memRequest, cpuRequest, count, topologyKey, maxSkew, matchingTSCLabels, labels)
 }
 
-func constructPodsWithoutTSC(namePrefix string, schedulerName string, nodeName string, memRequest, cpuRequest string, count int, labels map[string]string) ([]corev1.Pod, error) {
-	return constructPods(namePrefix, schedulerName, nodeName, memRequest, cpuRequest, count, nil, nil, nil, labels)
+func constructPodsWithoutTSC(namePrefix string, schedulerName string, nodeName string, priorityClassName string, memRequest, cpuRequest string, count int, labels map[string]string) ([]corev1.Pod, error) {
+	return constructPods(namePrefix, schedulerName, nodeName, priorityClassName, memRequest, cpuRequest, count, nil, nil, nil, labels)
 }
 
-func constructPods(namePrefix string, schedulerName string, nodeName string, memRequest, cpuRequest string, count int, topologyKey *string, maxSkew *int, matchingTSCLabels map[string]string, labels map[string]string) ([]corev1.Pod, error) {
+func constructPods(namePrefix string, schedulerName string, nodeName string, priorityClassName string, memRequest, cpuRequest string, count int, topologyKey *string, maxSkew *int, matchingTSCLabels map[string]string, labels map[string]string) ([]corev1.Pod, error) {
 	pods := make([]corev1.Pod, 0, count)
 	for i := 0; i < count; i++ {
 		suffix, err := simutil.GenerateRandomString(4)
@@ -196,6 +252,7 @@ func constructPods(namePrefix string, schedulerName string, nodeName string, mem
 			SchedulerName(schedulerName).
 			AddLabels(labels).
 			NodeName(nodeName).
+			PriorityClassName(priorityClassName).
 			RequestMemory(memRequest).
 			RequestCPU(cpuRequest).
 			TopologySpreadConstraint(topologyKey, maxSkew, matchingTSCLabels).
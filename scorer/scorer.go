@@ -0,0 +1,134 @@
+package scorer
+
+import (
+	"fmt"
+)
+
+// Names of the built-in scoring strategies. Except for the composite they mirror the cluster-autoscaler expanders.
+const (
+	CompositeStrategy  = "composite"
+	LeastWasteStrategy = "least-waste"
+	PriceStrategy      = "price"
+	MostPodsStrategy   = "most-pods"
+	PriorityStrategy   = "priority"
+	RandomStrategy     = "random"
+)
+
+// Strategies lists the names of all built-in scoring strategies.
+var Strategies = []string{CompositeStrategy, LeastWasteStrategy, PriceStrategy, MostPodsStrategy, PriorityStrategy, RandomStrategy}
+
+// Run holds the outcome of simulating the scale-up of a node pool that is scored by a Scorer.
+type Run struct {
+	NodePoolName string
+	InstanceType string
+	Zone         string
+	// Priority of the node pool, higher values are preferred.
+	Priority int
+	// WasteRatio is the share of the resources of the new nodes that stays unused, weighted over all resources.
+	WasteRatio float64
+	// CostRatio is the cost of the new nodes relative to the instance types of the shoot, the sum of the cost ratios of
+	// the instance types of all new nodes.
+	CostRatio float64
+	// UnscheduledRatio is the share of the pending pods that are still unscheduled after the run.
+	UnscheduledRatio float64
+	// NumAssignedPods is the number of pending pods assigned to the new nodes.
+	NumAssignedPods int
+}
+
+// Scorer scores simulation runs. The run with the lowest score wins, ties are broken randomly.
+type Scorer interface {
+	// Name returns the name of the strategy.
+	Name() string
+	// Score returns the score of the run.
+	Score(run Run) float64
+}
+
+// New returns the built-in Scorer of the given strategy. The weights are only used by the composite strategy, which
+// is also returned for an empty strategy.
+func New(strategy string, leastWasteWeight, leastCostWeight float64) (Scorer, error) {
+	switch strategy {
+	case "", CompositeStrategy:
+		return Composite{LeastWasteWeight: leastWasteWeight, LeastCostWeight: leastCostWeight}, nil
+	case LeastWasteStrategy:
+		return LeastWaste{}, nil
+	case PriceStrategy:
+		return Price{}, nil
+	case MostPodsStrategy:
+		return MostPods{}, nil
+	case PriorityStrategy:
+		return Priority{}, nil
+	case RandomStrategy:
+		return Random{}, nil
+	}
+	return nil, fmt.Errorf("unknown scoring strategy %q, expected one of %v", strategy, Strategies)
+}
+
+// Composite weighs the waste of the new node against its cost. The cost only matters as long as pods are left
+// unscheduled.
+type Composite struct {
+	LeastWasteWeight float64
+	LeastCostWeight  float64
+}
+
+func (c Composite) Name() string {
+	return CompositeStrategy
+}
+
+func (c Composite) Score(run Run) float64 {
+	return c.LeastWasteWeight*run.WasteRatio + run.UnscheduledRatio*c.LeastCostWeight*run.CostRatio
+}
+
+// LeastWaste prefers the node that leaves the least resources unused.
+type LeastWaste struct{}
+
+func (LeastWaste) Name() string {
+	return LeastWasteStrategy
+}
+
+func (LeastWaste) Score(run Run) float64 {
+	return run.WasteRatio
+}
+
+// Price prefers the scale-up with the lowest total price of its new nodes.
+type Price struct{}
+
+func (Price) Name() string {
+	return PriceStrategy
+}
+
+func (Price) Score(run Run) float64 {
+	return run.CostRatio
+}
+
+// MostPods prefers the node that hosts the most pending pods.
+type MostPods struct{}
+
+func (MostPods) Name() string {
+	return MostPodsStrategy
+}
+
+func (MostPods) Score(run Run) float64 {
+	return -float64(run.NumAssignedPods)
+}
+
+// Priority prefers the node pool with the highest priority.
+type Priority struct{}
+
+func (Priority) Name() string {
+	return PriorityStrategy
+}
+
+func (Priority) Score(run Run) float64 {
+	return -float64(run.Priority)
+}
+
+// Random scores all runs equally, so that the winner is picked randomly.
+type Random struct{}
+
+func (Random) Name() string {
+	return RandomStrategy
+}
+
+func (Random) Score(Run) float64 {
+	return 0
+}
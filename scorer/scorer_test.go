@@ -0,0 +1,34 @@
+package scorer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrategiesPickExpectedWinner(t *testing.T) {
+	runs := []Run{
+		{NodePoolName: "small", Priority: 1, WasteRatio: 0.1, CostRatio: 0.2, UnscheduledRatio: 0.5, NumAssignedPods: 2},
+		{NodePoolName: "large", Priority: 2, WasteRatio: 0.4, CostRatio: 0.8, UnscheduledRatio: 0, NumAssignedPods: 8},
+	}
+	winners := map[string]string{
+		CompositeStrategy:  "small",
+		LeastWasteStrategy: "small",
+		PriceStrategy:      "small",
+		MostPodsStrategy:   "large",
+		PriorityStrategy:   "large",
+	}
+	for strategy, expectedWinner := range winners {
+		s, err := New(strategy, 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, strategy, s.Name())
+		winner := slices.MinFunc(runs, func(a, b Run) int {
+			return int(s.Score(a)*100) - int(s.Score(b)*100)
+		})
+		assert.Equal(t, expectedWinner, winner.NodePoolName, strategy)
+	}
+
+	_, err := New("unknown", 1, 1)
+	assert.Error(t, err)
+}
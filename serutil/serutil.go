@@ -1,6 +1,7 @@
 package serutil
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
@@ -8,6 +9,9 @@ import (
 	gardencore "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -23,6 +27,9 @@ func init() {
 	utilruntime.Must(gardencore.AddToScheme(configScheme))
 	utilruntime.Must(corev1.AddToScheme(configScheme))
 	utilruntime.Must(machinev1alpha1.AddToScheme(configScheme))
+	utilruntime.Must(schedulingv1.AddToScheme(configScheme))
+	utilruntime.Must(policyv1.AddToScheme(configScheme))
+	utilruntime.Must(storagev1.AddToScheme(configScheme))
 	ser := json.NewSerializerWithOptions(json.DefaultMetaFactory, configScheme, configScheme, json.SerializerOptions{
 		Yaml:   true,
 		Pretty: false,
@@ -32,6 +39,9 @@ func init() {
 		gardencore.SchemeGroupVersion,
 		corev1.SchemeGroupVersion,
 		machinev1alpha1.SchemeGroupVersion,
+		schedulingv1.SchemeGroupVersion,
+		policyv1.SchemeGroupVersion,
+		storagev1.SchemeGroupVersion,
 	})
 	codec = serializer.NewCodecFactory(configScheme).CodecForVersions(ser, ser, versions, versions)
 	workingDir, err := os.Getwd()
@@ -120,3 +130,41 @@ func ReadPod(projRelPath string) (corev1.Pod, error) {
 	pod = *(obj.(*corev1.Pod))
 	return pod, nil
 }
+
+const yamlDocumentSeparator = "---\n"
+
+// EncodeObjects marshalls the given objects as a multi-document yaml.
+func EncodeObjects[T runtime.Object](objs []T) ([]byte, error) {
+	var sb strings.Builder
+	for i, obj := range objs {
+		bytes, err := runtime.Encode(codec, obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			sb.WriteString(yamlDocumentSeparator)
+		}
+		sb.Write(bytes)
+	}
+	return []byte(sb.String()), nil
+}
+
+// DecodeObjects unmarshalls the given multi-document yaml produced by EncodeObjects.
+func DecodeObjects[T runtime.Object](bytes []byte) ([]T, error) {
+	var items []T
+	for _, doc := range strings.Split(string(bytes), "\n"+yamlDocumentSeparator) {
+		if len(strings.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, err := runtime.Decode(codec, []byte(doc))
+		if err != nil {
+			return nil, err
+		}
+		item, ok := obj.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
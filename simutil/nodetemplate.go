@@ -0,0 +1,280 @@
+package simutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elankath/scaler-simulator/pricing"
+)
+
+// Defaults that Gardener applies to the kubelet configuration of shoots.
+var (
+	defaultMaxPods         int32 = 110
+	defaultKubeReserved          = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("80m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	defaultMemoryAvailable       = "100Mi"
+	defaultNodeFSAvailable       = "5%"
+)
+
+// WorkerKubeletConfig returns the kubelet configuration of the worker pool, which replaces the one of the shoot if set.
+func WorkerKubeletConfig(shoot *v1beta1.Shoot, worker *v1beta1.Worker) *v1beta1.KubeletConfig {
+	if kubelet := workerKubelet(worker); kubelet != nil {
+		return kubelet
+	}
+	return shoot.Spec.Kubernetes.Kubelet
+}
+
+// workerKubelet returns the kubelet configuration of the worker pool itself, which is all that is known without the
+// shoot.
+func workerKubelet(worker *v1beta1.Worker) *v1beta1.KubeletConfig {
+	if worker.Kubernetes == nil {
+		return nil
+	}
+	return worker.Kubernetes.Kubelet
+}
+
+// NewNodeTemplate synthesizes a node of the worker pool in the given zone for pools that have no node to copy from,
+// e.g. when they are scaled to zero. The capacity is derived from the cpu and memory of the instance type in the
+// pricing data and the volume of the pool, the allocatable resources subtract the resources reserved by the kubelet
+// configuration and its hard eviction thresholds. The node carries the labels and taints of the worker pool.
+func NewNodeTemplate(worker *v1beta1.Worker, kubelet *v1beta1.KubeletConfig, region, zone string) (*corev1.Node, error) {
+	instancePricing, ok := pricing.GetInstancePricing(worker.Machine.Type)
+	if !ok {
+		return nil, fmt.Errorf("cannot synthesize node of worker pool %q: unknown instance type %q", worker.Name, worker.Machine.Type)
+	}
+	maxPods := defaultMaxPods
+	if kubelet != nil && kubelet.MaxPods != nil {
+		maxPods = *kubelet.MaxPods
+	}
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(int64(instancePricing.VCPU*1000), resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(int64(instancePricing.Memory*1024*1024*1024), resource.BinarySI),
+		corev1.ResourcePods:   *resource.NewQuantity(int64(maxPods), resource.DecimalSI),
+	}
+	if worker.Volume != nil && worker.Volume.VolumeSize != "" {
+		volumeSize, err := resource.ParseQuantity(worker.Volume.VolumeSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume size of worker pool %q: %w", worker.Name, err)
+		}
+		capacity[corev1.ResourceEphemeralStorage] = volumeSize
+	}
+	allocatable, err := computeAllocatable(capacity, kubelet)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute allocatable resources of worker pool %q: %w", worker.Name, err)
+	}
+
+	labels := make(map[string]string, len(worker.Labels)+7)
+	architecture := "amd64"
+	if worker.Machine.Architecture != nil {
+		architecture = *worker.Machine.Architecture
+	}
+	labels["kubernetes.io/arch"] = architecture
+	labels["kubernetes.io/os"] = "linux"
+	if region != "" {
+		labels["topology.kubernetes.io/region"] = region
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   worker.Name + "-template",
+			Labels: labels,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    capacity,
+			Allocatable: allocatable,
+			Phase:       corev1.NodeRunning,
+		},
+	}
+	applyWorkerSpec(node, worker, zone)
+	return node, nil
+}
+
+// NewWorkerNode constructs a node of the worker pool in the given zone from a reference node of the same instance type,
+// which may belong to another pool. Labels, annotations and taints are those of the worker pool, only the labels
+// of the reference node that are not set by any worker pool of the shoot are kept. The allocatable resources of the
+// reference node are adjusted by the difference of the kubelet configurations of both pools. Without a reference node
+// the node is synthesized with NewNodeTemplate. The shoot may be nil if it is unknown.
+func NewWorkerNode(shoot *v1beta1.Shoot, worker *v1beta1.Worker, referenceNode *corev1.Node, zone string) (*corev1.Node, error) {
+	kubelet := workerKubelet(worker)
+	var region string
+	if shoot != nil {
+		kubelet = WorkerKubeletConfig(shoot, worker)
+		region = shoot.Spec.Region
+	}
+	if referenceNode == nil {
+		return NewNodeTemplate(worker, kubelet, region, zone)
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: make(map[string]string, len(referenceNode.Labels)),
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    referenceNode.Status.Capacity.DeepCopy(),
+			Allocatable: referenceNode.Status.Allocatable.DeepCopy(),
+			Phase:       corev1.NodeRunning,
+		},
+	}
+	for key, value := range referenceNode.Labels {
+		node.Labels[key] = value
+	}
+	delete(node.Labels, "app.kubernetes.io/existing-node")
+	delete(node.Labels, "kubernetes.io/hostname")
+	referencePool := referenceNode.Labels["worker.gardener.cloud/pool"]
+	var referenceKubelet *v1beta1.KubeletConfig
+	if shoot != nil {
+		for i := range shoot.Spec.Provider.Workers {
+			otherWorker := &shoot.Spec.Provider.Workers[i]
+			for key := range otherWorker.Labels {
+				delete(node.Labels, key)
+			}
+			if otherWorker.Name == referencePool {
+				referenceKubelet = WorkerKubeletConfig(shoot, otherWorker)
+			}
+		}
+	}
+	if referencePool != worker.Name {
+		if kubelet != nil && kubelet.MaxPods != nil {
+			node.Status.Capacity[corev1.ResourcePods] = *resource.NewQuantity(int64(*kubelet.MaxPods), resource.DecimalSI)
+			node.Status.Allocatable[corev1.ResourcePods] = *resource.NewQuantity(int64(*kubelet.MaxPods), resource.DecimalSI)
+		}
+		if err := adjustAllocatable(node.Status.Allocatable, node.Status.Capacity, referenceKubelet, kubelet); err != nil {
+			return nil, fmt.Errorf("cannot compute allocatable resources of worker pool %q: %w", worker.Name, err)
+		}
+	}
+	applyWorkerSpec(node, worker, zone)
+	return node, nil
+}
+
+// applyWorkerSpec sets the labels, annotations and taints of the worker pool on the node.
+func applyWorkerSpec(node *corev1.Node, worker *v1beta1.Worker, zone string) {
+	for key, value := range worker.Labels {
+		node.Labels[key] = value
+	}
+	node.Labels["node.kubernetes.io/instance-type"] = worker.Machine.Type
+	node.Labels["topology.kubernetes.io/zone"] = zone
+	node.Labels["worker.gardener.cloud/pool"] = worker.Name
+	node.Labels["worker.garden.sapcloud.io/group"] = worker.Name
+	if len(worker.Annotations) > 0 {
+		node.Annotations = make(map[string]string, len(worker.Annotations))
+		for key, value := range worker.Annotations {
+			node.Annotations[key] = value
+		}
+	}
+	node.Spec.Taints = append([]corev1.Taint(nil), worker.Taints...)
+}
+
+// adjustAllocatable changes the allocatable resources of a node with the kubelet configuration from by the difference
+// that the kubelet configuration to would make.
+func adjustAllocatable(allocatable, capacity corev1.ResourceList, from, to *v1beta1.KubeletConfig) error {
+	fromAllocatable, err := computeAllocatable(capacity, from)
+	if err != nil {
+		return err
+	}
+	toAllocatable, err := computeAllocatable(capacity, to)
+	if err != nil {
+		return err
+	}
+	for name, quantity := range allocatable {
+		if name == corev1.ResourcePods {
+			continue
+		}
+		quantity.Add(toAllocatable[name])
+		quantity.Sub(fromAllocatable[name])
+		if quantity.Sign() < 0 {
+			quantity = *resource.NewQuantity(0, quantity.Format)
+		}
+		allocatable[name] = quantity
+	}
+	return nil
+}
+
+// computeAllocatable subtracts the kube and system reserved resources and the hard eviction thresholds of the kubelet
+// configuration from the capacity like the kubelet does.
+func computeAllocatable(capacity corev1.ResourceList, kubelet *v1beta1.KubeletConfig) (corev1.ResourceList, error) {
+	var kubeReserved, systemReserved *v1beta1.KubeletConfigReserved
+	memoryAvailable, nodeFSAvailable := defaultMemoryAvailable, defaultNodeFSAvailable
+	if kubelet != nil {
+		kubeReserved, systemReserved = kubelet.KubeReserved, kubelet.SystemReserved
+		if kubelet.EvictionHard != nil && kubelet.EvictionHard.MemoryAvailable != nil {
+			memoryAvailable = *kubelet.EvictionHard.MemoryAvailable
+		}
+		if kubelet.EvictionHard != nil && kubelet.EvictionHard.NodeFSAvailable != nil {
+			nodeFSAvailable = *kubelet.EvictionHard.NodeFSAvailable
+		}
+	}
+	reserved := corev1.ResourceList{}
+	addReserved(reserved, kubeReserved, defaultKubeReserved)
+	addReserved(reserved, systemReserved, nil)
+	for name, threshold := range map[corev1.ResourceName]string{corev1.ResourceMemory: memoryAvailable, corev1.ResourceEphemeralStorage: nodeFSAvailable} {
+		quantity, ok := capacity[name]
+		if !ok {
+			continue
+		}
+		evictionThreshold, err := parseThreshold(threshold, quantity)
+		if err != nil {
+			return nil, err
+		}
+		total := reserved[name]
+		total.Add(evictionThreshold)
+		reserved[name] = total
+	}
+
+	allocatable := capacity.DeepCopy()
+	for name, quantity := range reserved {
+		value, ok := allocatable[name]
+		if !ok {
+			continue
+		}
+		value.Sub(quantity)
+		if value.Sign() < 0 {
+			value = *resource.NewQuantity(0, value.Format)
+		}
+		allocatable[name] = value
+	}
+	return allocatable, nil
+}
+
+// addReserved adds the reserved resources to the list. Resources that are not reserved fall back to the given
+// defaults.
+func addReserved(list corev1.ResourceList, reserved *v1beta1.KubeletConfigReserved, defaults corev1.ResourceList) {
+	if reserved == nil {
+		reserved = &v1beta1.KubeletConfigReserved{}
+	}
+	for name, quantity := range map[corev1.ResourceName]*resource.Quantity{
+		corev1.ResourceCPU:              reserved.CPU,
+		corev1.ResourceMemory:           reserved.Memory,
+		corev1.ResourceEphemeralStorage: reserved.EphemeralStorage,
+	} {
+		if quantity == nil {
+			defaultQuantity, ok := defaults[name]
+			if !ok {
+				continue
+			}
+			quantity = &defaultQuantity
+		}
+		total := list[name]
+		total.Add(*quantity)
+		list[name] = total
+	}
+}
+
+// parseThreshold parses an eviction threshold that is either a quantity or a percentage of the capacity.
+func parseThreshold(threshold string, capacity resource.Quantity) (resource.Quantity, error) {
+	if percent, ok := strings.CutSuffix(threshold, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid eviction threshold %q: %w", threshold, err)
+		}
+		return *resource.NewQuantity(int64(float64(capacity.Value())*value/100), capacity.Format), nil
+	}
+	quantity, err := resource.ParseQuantity(threshold)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid eviction threshold %q: %w", threshold, err)
+	}
+	return quantity, nil
+}
@@ -0,0 +1,98 @@
+package simutil
+
+import (
+	"testing"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewNodeTemplate(t *testing.T) {
+	worker := &v1beta1.Worker{
+		Name:    "p1",
+		Machine: v1beta1.Machine{Type: "m5.large"},
+		Labels:  map[string]string{"pool": "gpu"},
+		Taints:  []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		Volume:  &v1beta1.Volume{VolumeSize: "50Gi"},
+		Zones:   []string{"eu-west-1a"},
+	}
+	node, err := NewNodeTemplate(worker, nil, "eu-west-1", "eu-west-1a")
+	assert.NoError(t, err)
+	assert.Equal(t, "gpu", node.Labels["pool"])
+	assert.Equal(t, "m5.large", node.Labels["node.kubernetes.io/instance-type"])
+	assert.Equal(t, "eu-west-1a", node.Labels["topology.kubernetes.io/zone"])
+	assert.Equal(t, "p1", node.Labels["worker.gardener.cloud/pool"])
+	assert.Equal(t, worker.Taints, node.Spec.Taints)
+	assert.Equal(t, int64(2000), node.Status.Capacity.Cpu().MilliValue())
+	assert.Equal(t, int64(1920), node.Status.Allocatable.Cpu().MilliValue())
+	assert.Equal(t, int64(7*1024-100)*1024*1024, node.Status.Allocatable.Memory().Value())
+	assert.Equal(t, int64(110), node.Status.Allocatable.Pods().Value())
+	assert.Equal(t, int64(50*1024*1024*1024*95/100), node.Status.Allocatable.StorageEphemeral().Value())
+
+	kubelet := &v1beta1.KubeletConfig{
+		MaxPods:        ptr.To[int32](64),
+		KubeReserved:   &v1beta1.KubeletConfigReserved{Memory: ptr.To(resource.MustParse("512Mi"))},
+		SystemReserved: &v1beta1.KubeletConfigReserved{CPU: ptr.To(resource.MustParse("100m"))},
+		EvictionHard:   &v1beta1.KubeletConfigEviction{MemoryAvailable: ptr.To("10%")},
+	}
+	node, err = NewNodeTemplate(worker, kubelet, "eu-west-1", "eu-west-1a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1820), node.Status.Allocatable.Cpu().MilliValue())
+	assert.Equal(t, int64(8*1024*1024*1024-858993459-512*1024*1024), node.Status.Allocatable.Memory().Value())
+	assert.Equal(t, int64(64), node.Status.Allocatable.Pods().Value())
+
+	worker.Machine.Type = "unknown"
+	_, err = NewNodeTemplate(worker, nil, "eu-west-1", "eu-west-1a")
+	assert.Error(t, err)
+}
+
+func TestNewWorkerNode(t *testing.T) {
+	shoot := &v1beta1.Shoot{Spec: v1beta1.ShootSpec{
+		Region: "eu-west-1",
+		Provider: v1beta1.Provider{Workers: []v1beta1.Worker{
+			{
+				Name:    "p1",
+				Machine: v1beta1.Machine{Type: "m5.large"},
+				Labels:  map[string]string{"role": "general"},
+			},
+			{
+				Name:        "p2",
+				Machine:     v1beta1.Machine{Type: "m5.large"},
+				Labels:      map[string]string{"dedicated": "batch"},
+				Annotations: map[string]string{"team": "data"},
+				Taints:      []corev1.Taint{{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule}},
+				Kubernetes: &v1beta1.WorkerKubernetes{Kubelet: &v1beta1.KubeletConfig{
+					MaxPods:      ptr.To[int32](64),
+					KubeReserved: &v1beta1.KubeletConfigReserved{Memory: ptr.To(resource.MustParse("2Gi"))},
+				}},
+			},
+		}},
+	}}
+	referenceNode, err := NewNodeTemplate(&shoot.Spec.Provider.Workers[0], nil, "eu-west-1", "eu-west-1a")
+	assert.NoError(t, err)
+	referenceNode.Labels["app.kubernetes.io/existing-node"] = "true"
+	referenceNode.Labels["kubernetes.io/hostname"] = "ip-10-0-0-1"
+
+	node, err := NewWorkerNode(shoot, &shoot.Spec.Provider.Workers[1], referenceNode, "eu-west-1b")
+	assert.NoError(t, err)
+	assert.NotContains(t, node.Labels, "role")
+	assert.NotContains(t, node.Labels, "app.kubernetes.io/existing-node")
+	assert.NotContains(t, node.Labels, "kubernetes.io/hostname")
+	assert.Equal(t, "batch", node.Labels["dedicated"])
+	assert.Equal(t, "p2", node.Labels["worker.gardener.cloud/pool"])
+	assert.Equal(t, "eu-west-1b", node.Labels["topology.kubernetes.io/zone"])
+	assert.Equal(t, "amd64", node.Labels["kubernetes.io/arch"])
+	assert.Equal(t, map[string]string{"team": "data"}, node.Annotations)
+	assert.Equal(t, shoot.Spec.Provider.Workers[1].Taints, node.Spec.Taints)
+	assert.Equal(t, int64(1920), node.Status.Allocatable.Cpu().MilliValue())
+	assert.Equal(t, int64(6*1024-100)*1024*1024, node.Status.Allocatable.Memory().Value())
+	assert.Equal(t, int64(64), node.Status.Allocatable.Pods().Value())
+
+	node, err = NewWorkerNode(shoot, &shoot.Spec.Provider.Workers[0], referenceNode, "eu-west-1a")
+	assert.NoError(t, err)
+	assert.Equal(t, referenceNode.Status.Allocatable, node.Status.Allocatable)
+	assert.Empty(t, node.Spec.Taints)
+}
@@ -20,6 +20,7 @@ type PodBuilder struct {
 	objectMeta      metav1.ObjectMeta
 	schedulerName   string
 	nodeName        string
+	priorityClass   string
 	resourceRequest corev1.ResourceList
 	tsc             []corev1.TopologySpreadConstraint
 }
@@ -76,6 +77,11 @@ func (p *PodBuilder) NodeName(nodeName string) *PodBuilder {
 	return p
 }
 
+func (p *PodBuilder) PriorityClassName(priorityClassName string) *PodBuilder {
+	p.priorityClass = priorityClassName
+	return p
+}
+
 func (p *PodBuilder) TopologySpreadConstraint(topologyKey *string, maxSkew *int, labels map[string]string) *PodBuilder {
 	if topologyKey == nil || maxSkew == nil || labels == nil {
 		return p
@@ -112,6 +118,7 @@ func (p *PodBuilder) Build() (*corev1.Pod, error) {
 				},
 			},
 			NodeName:                      p.nodeName,
+			PriorityClassName:             p.priorityClass,
 			TerminationGracePeriodSeconds: ptr.To(int64(0)),
 			TopologySpreadConstraints:     p.tsc,
 		},
@@ -0,0 +1,56 @@
+package simutil
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/kubernetes/pkg/apis/scheduling"
+)
+
+// systemPriorityClassValues holds the values of the PriorityClasses that every cluster has built in.
+var systemPriorityClassValues = map[string]int32{
+	scheduling.SystemClusterCritical: scheduling.SystemCriticalPriority,
+	scheduling.SystemNodeCritical:    scheduling.SystemCriticalPriority + 1000,
+}
+
+// ResolvePodPriorities sets the priority and preemption policy of the pods from their PriorityClass like the Priority
+// admission plugin. Pods without a PriorityClass get the global default class with the highest value or priority 0.
+// Pods whose priority is already set are left unchanged.
+func ResolvePodPriorities(pods []corev1.Pod, priorityClasses []schedulingv1.PriorityClass) error {
+	byName := make(map[string]*schedulingv1.PriorityClass, len(priorityClasses))
+	var defaultClass *schedulingv1.PriorityClass
+	for i := range priorityClasses {
+		pc := &priorityClasses[i]
+		byName[pc.Name] = pc
+		if pc.GlobalDefault && (defaultClass == nil || pc.Value > defaultClass.Value) {
+			defaultClass = pc
+		}
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.Priority != nil {
+			continue
+		}
+		var priority int32 = scheduling.DefaultPriorityWhenNoDefaultClassExists
+		pc := defaultClass
+		if pod.Spec.PriorityClassName != "" {
+			var ok bool
+			if pc, ok = byName[pod.Spec.PriorityClassName]; !ok {
+				value, ok := systemPriorityClassValues[pod.Spec.PriorityClassName]
+				if !ok {
+					return fmt.Errorf("priority class %q of pod %s/%s not found", pod.Spec.PriorityClassName, pod.Namespace, pod.Name)
+				}
+				priority = value
+			}
+		}
+		if pc != nil {
+			priority = pc.Value
+			if pod.Spec.PreemptionPolicy == nil {
+				pod.Spec.PreemptionPolicy = pc.PreemptionPolicy
+			}
+		}
+		pod.Spec.Priority = &priority
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package simutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestResolvePodPriorities(t *testing.T) {
+	never := corev1.PreemptNever
+	priorityClasses := []schedulingv1.PriorityClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Value: 100, GlobalDefault: true},
+		{ObjectMeta: metav1.ObjectMeta{Name: "overprovisioning"}, Value: -20, PreemptionPolicy: &never},
+	}
+	pods := []corev1.Pod{
+		{},
+		{Spec: corev1.PodSpec{PriorityClassName: "overprovisioning"}},
+		{Spec: corev1.PodSpec{PriorityClassName: "system-node-critical"}},
+		{Spec: corev1.PodSpec{PriorityClassName: "overprovisioning", Priority: ptr.To[int32](5)}},
+	}
+	assert.NoError(t, ResolvePodPriorities(pods, priorityClasses))
+	assert.Equal(t, int32(100), *pods[0].Spec.Priority)
+	assert.Nil(t, pods[0].Spec.PreemptionPolicy)
+	assert.Equal(t, int32(-20), *pods[1].Spec.Priority)
+	assert.Equal(t, &never, pods[1].Spec.PreemptionPolicy)
+	assert.Equal(t, int32(2000001000), *pods[2].Spec.Priority)
+	assert.Equal(t, int32(5), *pods[3].Spec.Priority)
+
+	pods = []corev1.Pod{{}}
+	assert.NoError(t, ResolvePodPriorities(pods, priorityClasses[1:]))
+	assert.Equal(t, int32(0), *pods[0].Spec.Priority)
+
+	pods = []corev1.Pod{{Spec: corev1.PodSpec{PriorityClassName: "missing"}}}
+	assert.Error(t, ResolvePodPriorities(pods, priorityClasses))
+}
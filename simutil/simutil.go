@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/elankath/scaler-simulator/pricing"
+	"github.com/elankath/scaler-simulator/resutil"
 	"github.com/samber/lo"
 	"golang.org/x/exp/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -61,24 +62,6 @@ func GetFailedSchedulingEvents(ctx context.Context, a scalesim.VirtualClusterAcc
 	return failedSchedulingEvents, nil
 }
 
-func GetPodSchedulingEvents(ctx context.Context, vca scalesim.VirtualClusterAccess, podNames []string, since time.Time) ([]corev1.Event, error) {
-	var podEvents []corev1.Event
-	events, err := vca.ListEvents(ctx)
-	if err != nil {
-		return nil, err
-	}
-	for _, event := range events {
-		sinceTime := metav1.NewTime(since)
-		if event.EventTime.BeforeTime(&sinceTime) {
-			continue
-		}
-		if slices.Contains(podNames, event.InvolvedObject.Name) {
-			podEvents = append(podEvents, event)
-		}
-	}
-	return podEvents, nil
-}
-
 func PrintScheduledPodEvents(ctx context.Context, a scalesim.VirtualClusterAccess, since time.Time, w http.ResponseWriter) error {
 	events, err := a.ListEvents(ctx)
 	if err != nil {
@@ -104,63 +87,34 @@ func PrintScheduledPodEvents(ctx context.Context, a scalesim.VirtualClusterAcces
 	return nil
 }
 
-func WaitForAndRecordPodSchedulingEvents(ctx context.Context, vca scalesim.VirtualClusterAccess, w http.ResponseWriter, since time.Time, pods []corev1.Pod, timeout time.Duration) (scheduledPodNames sets.Set[string], unscheduledPodNames sets.Set[string], err error) {
-	startTime := time.Now()
-	defer func() {
-		slog.Info(fmt.Sprintf("#WaitForAndRecordPodSchedulingEvents took %f seconds", time.Since(startTime).Seconds()))
-	}()
-	tick := time.NewTicker(timeout)
-	defer tick.Stop()
-	pollTick := time.NewTicker(100 * time.Millisecond)
-	defer pollTick.Stop()
-
-	podNames := PodNames(pods)
+// ApplySchedulingResults returns copies of the given pods with the node name set to the node that the scheduler bound
+// the pod to, as reported by the given scheduling results.
+func ApplySchedulingResults(pods []corev1.Pod, results []scalesim.PodSchedulingResult) []corev1.Pod {
+	nodeNames := make(map[types.NamespacedName]string, len(results))
+	for _, result := range results {
+		nodeNames[result.Pod] = result.NodeName
+	}
+	scheduledPods := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		podCopy := pod.DeepCopy()
+		podCopy.Spec.NodeName = nodeNames[types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}]
+		scheduledPods = append(scheduledPods, *podCopy)
+	}
+	return scheduledPods
+}
+
+// SplitSchedulingResults returns the names of the pods that were scheduled and of the pods that are unschedulable.
+func SplitSchedulingResults(results []scalesim.PodSchedulingResult) (scheduledPodNames sets.Set[string], unscheduledPodNames sets.Set[string]) {
 	scheduledPodNames = sets.New[string]()
 	unscheduledPodNames = sets.New[string]()
-
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			return scheduledPodNames, unscheduledPodNames, fmt.Errorf("context cancelled, timeout waiting for pod events: %w", ctx.Err())
-		case <-tick.C:
-			return scheduledPodNames, unscheduledPodNames, fmt.Errorf("timeout waiting for pod events")
-		case <-pollTick.C:
-			events, err := GetPodSchedulingEvents(ctx, vca, podNames, since)
-			if err != nil {
-				slog.Error("cannot get pod scheduling events, this will be retried", "error", err)
-			}
-			for _, event := range events {
-				switch event.Reason {
-				case "FailedScheduling":
-					//webutil.Log(w, fmt.Sprintf("Pod %s failed scheduling at %s", event.InvolvedObject.Name, event.EventTime.Time.String()))
-					unscheduledPodNames.Insert(event.InvolvedObject.Name)
-				case "Scheduled":
-
-					//webutil.Log(w, fmt.Sprintf("Pod %s scheduled at %s to %s", event.InvolvedObject.Name, event.EventTime.Time.String()))
-					scheduledPodNames.Insert(event.InvolvedObject.Name)
-					podNames = slices.DeleteFunc(podNames, func(podName string) bool {
-						return podName == event.InvolvedObject.Name
-					})
-					unscheduledPodNames.Delete(event.InvolvedObject.Name)
-				}
-			}
-			//webutil.Log(w, fmt.Sprintf("Scheduled Pods: %d, Unscheduled Pods: %d, Total Pods : %d", len(scheduledPodNames), len(unscheduledPodNames), len(pods)))
-			slog.Info(fmt.Sprintf("Scheduled Pods: %d, Unscheduled Pods: %d, Total Pods : %d", len(scheduledPodNames), len(unscheduledPodNames), len(pods)))
-			if len(scheduledPodNames)+len(unscheduledPodNames) == len(pods) {
-				break loop
-			}
-			//since = time.Now()
+	for _, result := range results {
+		if result.IsScheduled() {
+			scheduledPodNames.Insert(result.Pod.Name)
+		} else {
+			unscheduledPodNames.Insert(result.Pod.Name)
 		}
 	}
-
-	/*
-		Run #1
-			1 pod got scheduled and 11 did not. There are 11 unscheduled events.
-		Run #2
-
-	*/
-	return scheduledPodNames, unscheduledPodNames, nil
+	return scheduledPodNames, unscheduledPodNames
 }
 
 func WaitTillNoUnscheduledPodsOrTimeout(ctx context.Context, access scalesim.VirtualClusterAccess, timeout time.Duration, since time.Time) (int, error) {
@@ -178,7 +132,7 @@ func WaitTillNoUnscheduledPodsOrTimeout(ctx context.Context, access scalesim.Vir
 			eventList, err := GetFailedSchedulingEvents(ctx, access, since)
 			numFailedUnscheduled = len(eventList)
 			if err != nil {
-				return numFailedUnscheduled, fmt.Errorf("cant get failed scheduling events due to: w", err)
+				return numFailedUnscheduled, fmt.Errorf("cant get failed scheduling events due to: %w", err)
 			}
 			if len(eventList) == 0 {
 				slog.Info("no FailedScheduling events present.")
@@ -278,26 +232,14 @@ func CreateNodeInWorkerGroupForZone(ctx context.Context, a scalesim.VirtualClust
 		}
 	}
 
-	if deployedNode == nil {
-		return false, nil
-	}
-	node := corev1.Node{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", wg.Name),
-			Namespace:    "default",
-			//TODO Change k8s hostname labels
-			Labels: deployedNode.Labels,
-		},
-		Status: corev1.NodeStatus{
-			Allocatable: deployedNode.Status.Allocatable,
-			Capacity:    deployedNode.Status.Capacity,
-			Phase:       corev1.NodeRunning,
-		},
+	node, err := NewWorkerNode(nil, wg, deployedNode, zone)
+	if err != nil {
+		return false, err
 	}
-	node.Labels["topology.kubernetes.io/zone"] = zone
+	node.GenerateName = fmt.Sprintf("%s-", wg.Name)
+	node.Namespace = "default"
 	node.Labels["topology.kubernetes.io/region"] = region
-	delete(node.Labels, "app.kubernetes.io/existing-node")
-	if err := a.AddNodesAndUpdateLabels(ctx, &node); err != nil {
+	if err := a.AddNodesAndUpdateLabels(ctx, node); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -340,28 +282,25 @@ func CreateNodeInWorkerGroup(ctx context.Context, a scalesim.VirtualClusterAcces
 		}
 	}
 
-	if deployedNode == nil {
+	zone := ""
+	if deployedNode != nil {
+		zone = deployedNode.Labels["topology.kubernetes.io/zone"]
+	} else if len(wg.Zones) > 0 {
+		zone = wg.Zones[0]
+	} else {
 		return nil, errors.New(fmt.Sprintf("cannot find a deployed node in the worker group for pool : %s", wg.Name))
 	}
-	node := corev1.Node{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", wg.Name),
-			Namespace:    "default",
-			//TODO Change k8s hostname labels
-			Labels: deployedNode.Labels,
-		},
-		Status: corev1.NodeStatus{
-			Allocatable: deployedNode.Status.Allocatable,
-			Capacity:    deployedNode.Status.Capacity,
-			Phase:       corev1.NodeRunning,
-		},
-	}
-	delete(node.Labels, "app.kubernetes.io/existing-node")
+	node, err := NewWorkerNode(nil, wg, deployedNode, zone)
+	if err != nil {
+		return nil, err
+	}
+	node.GenerateName = fmt.Sprintf("%s-", wg.Name)
+	node.Namespace = "default"
 	oldNodes, err := GetNodesSet(ctx, a)
 	if err != nil {
 		return nil, err
 	}
-	if err := a.AddNodesAndUpdateLabels(ctx, &node); err != nil {
+	if err := a.AddNodesAndUpdateLabels(ctx, node); err != nil {
 		return nil, err
 	}
 	newNodes, err := GetNodesSet(ctx, a)
@@ -375,7 +314,7 @@ func CreateNodeInWorkerGroup(ctx context.Context, a scalesim.VirtualClusterAcces
 	addedNodes := maps.Values(newNodes)
 
 	if len(addedNodes) > 0 {
-		if err = a.RemoveAllTaintsFromVirtualNode(ctx, addedNodes[0].Name); err != nil {
+		if err = a.RemoveTaintFromVirtualNode(ctx, addedNodes[0].Name, corev1.TaintNodeNotReady); err != nil {
 			return nil, err
 		}
 		return addedNodes[0], nil
@@ -603,8 +542,15 @@ func PodNames(pods []corev1.Pod) []string {
 	})
 }
 
+// Label and taint of the nodes synced from the shoot. The taint keeps pods off the existing nodes as long as their
+// scheduled pods are not synced.
+const (
+	ExistingNodeLabel    = "app.kubernetes.io/existing-node"
+	ExistingNodeTaintKey = "app.kubernetes.io/existing-node-no-schedule"
+)
+
 func IsExistingNode(n *corev1.Node) bool {
-	return n.Labels["app.kubernetes.io/existing-node"] == "true"
+	return n.Labels[ExistingNodeLabel] == "true"
 }
 
 func DeleteNodeAndPods(ctx context.Context, w http.ResponseWriter, access scalesim.VirtualClusterAccess, node *corev1.Node, pods []corev1.Pod) error {
@@ -642,8 +588,9 @@ func GenerateRandomString(length int) (string, error) {
 func ComputeNodeWaste(node *corev1.Node, pods []corev1.Pod) resource.Quantity {
 	totalAllocatedMem := node.Status.Allocatable.Memory()
 	var totalConsumedMem resource.Quantity
-	for _, pod := range pods {
-		totalConsumedMem.Add(*pod.Spec.Containers[0].Resources.Requests.Memory())
+	for i := range pods {
+		podRequests := resutil.PodRequests(&pods[i])
+		totalConsumedMem.Add(*podRequests.Memory())
 	}
 	totalAllocatedMem.Sub(totalConsumedMem)
 	return *totalAllocatedMem
@@ -0,0 +1,115 @@
+package simutil
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+// defaultMaxSurge is the max surge that Gardener defaults worker pools to.
+var defaultMaxSurge = intstr.FromInt32(1)
+
+// ComputeZoneLimits computes the limits of the machine deployments of the worker pool in each of its zones the way the
+// Gardener provider extensions do. Minimum and maximum are distributed evenly over the zones with the first zones
+// getting one machine more for uneven numbers, max surge is distributed in relation to the maximum.
+func ComputeZoneLimits(worker *v1beta1.Worker) map[string]scalesim.ZoneLimits {
+	maxSurge := defaultMaxSurge
+	if worker.MaxSurge != nil {
+		maxSurge = *worker.MaxSurge
+	}
+	numZones := int32(len(worker.Zones))
+	limits := make(map[string]scalesim.ZoneLimits, numZones)
+	for i, zone := range worker.Zones {
+		zoneIndex := int32(i)
+		zoneMax := distributeOverZones(zoneIndex, worker.Maximum, numZones)
+		zoneMaxSurge := distributePositiveIntOrPercent(zoneIndex, maxSurge, numZones, worker.Maximum)
+		// like the machine-controller-manager, percentages of max surge are rounded up.
+		surge, err := intstr.GetScaledValueFromIntOrPercent(&zoneMaxSurge, int(zoneMax), true)
+		if err != nil {
+			surge = 0
+		}
+		limits[zone] = scalesim.ZoneLimits{
+			Min:      distributeOverZones(zoneIndex, worker.Minimum, numZones),
+			Max:      zoneMax,
+			MaxSurge: int32(surge),
+		}
+	}
+	return limits
+}
+
+// ZoneSize returns the number of nodes in the zone that count against its limits. Cordoned nodes are replaced during
+// a rolling update by up to max surge new ones, which are not counted. Zones below their minimum count as the minimum
+// because the missing machines are created anyway.
+func ZoneSize(nodes []corev1.Node, zone string, limits scalesim.ZoneLimits) int32 {
+	var size, cordoned int32
+	for _, node := range nodes {
+		if node.Labels["topology.kubernetes.io/zone"] != zone {
+			continue
+		}
+		size++
+		if node.Spec.Unschedulable {
+			cordoned++
+		}
+	}
+	size -= min(cordoned, limits.MaxSurge)
+	return max(size, limits.Min)
+}
+
+// distributeOverZones returns the share of size that the zone of the given index gets, see
+// github.com/gardener/gardener/extensions/pkg/controller/worker.DistributeOverZones.
+func distributeOverZones(zoneIndex, size, numZones int32) int32 {
+	if numZones == 0 {
+		return 0
+	}
+	share := size / numZones
+	if zoneIndex < size%numZones {
+		share++
+	}
+	return share
+}
+
+// distributePositiveIntOrPercent returns the share of an absolute value or percentage that the zone of the given index
+// gets in relation to total, see
+// github.com/gardener/gardener/extensions/pkg/controller/worker.DistributePositiveIntOrPercent.
+func distributePositiveIntOrPercent(zoneIndex int32, intOrPercent intstr.IntOrString, numZones, total int32) intstr.IntOrString {
+	if intOrPercent.Type == intstr.Int {
+		return intstr.FromInt32(distributeOverZones(zoneIndex, intOrPercent.IntVal, numZones))
+	}
+	percent, err := intstr.GetScaledValueFromIntOrPercent(&intOrPercent, 100, false)
+	if err != nil || numZones == 0 || total%numZones == 0 {
+		return intOrPercent
+	}
+	zoneTotal := distributeOverZones(zoneIndex, total, numZones)
+	ratio := 100.0 / (float64(total) / float64(numZones)) * float64(zoneTotal)
+	return intstr.FromString(fmt.Sprintf("%d%%", int(math.Ceil(ratio*float64(percent)/100.0))))
+}
+
+// MachineDeploymentNodeGroup returns the worker pool and zone of the machine deployment. They are taken from the labels
+// of its node template and otherwise from its name, which Gardener builds as <shoot namespace>-<pool>-z<zone index>
+// with the zone index starting at 1.
+func MachineDeploymentNodeGroup(shoot *v1beta1.Shoot, mcd *machinev1alpha1.MachineDeployment) (pool, zone string, ok bool) {
+	labels := mcd.Spec.Template.Spec.NodeTemplateSpec.Labels
+	pool = labels["worker.gardener.cloud/pool"]
+	zone = labels["topology.kubernetes.io/zone"]
+	if zone == "" {
+		zone = labels["topology.ebs.csi.aws.com/zone"]
+	}
+	if pool != "" && zone != "" {
+		return pool, zone, true
+	}
+	for _, worker := range shoot.Spec.Provider.Workers {
+		for i, workerZone := range worker.Zones {
+			if strings.HasSuffix(mcd.Name, fmt.Sprintf("-%s-z%d", worker.Name, i+1)) {
+				return worker.Name, workerZone, true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,62 @@
+package simutil
+
+import (
+	"testing"
+
+	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+func TestComputeZoneLimits(t *testing.T) {
+	maxSurge := intstr.FromString("50%")
+	worker := &v1beta1.Worker{Minimum: 2, Maximum: 7, MaxSurge: &maxSurge, Zones: []string{"a", "b", "c"}}
+	assert.Equal(t, map[string]scalesim.ZoneLimits{
+		"a": {Min: 1, Max: 3, MaxSurge: 2},
+		"b": {Min: 1, Max: 2, MaxSurge: 1},
+		"c": {Min: 0, Max: 2, MaxSurge: 1},
+	}, ComputeZoneLimits(worker))
+
+	worker = &v1beta1.Worker{Maximum: 6, Zones: []string{"a", "b", "c"}}
+	assert.Equal(t, scalesim.ZoneLimits{Max: 2, MaxSurge: 1}, ComputeZoneLimits(worker)["a"])
+	assert.Equal(t, scalesim.ZoneLimits{Max: 2}, ComputeZoneLimits(worker)["c"])
+}
+
+func TestZoneSize(t *testing.T) {
+	newNode := func(zone string, cordoned bool) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"topology.kubernetes.io/zone": zone}},
+			Spec:       corev1.NodeSpec{Unschedulable: cordoned},
+		}
+	}
+	nodes := []corev1.Node{newNode("a", false), newNode("a", true), newNode("a", true), newNode("b", false)}
+	assert.Equal(t, int32(2), ZoneSize(nodes, "a", scalesim.ZoneLimits{Max: 3, MaxSurge: 1}))
+	assert.Equal(t, int32(3), ZoneSize(nodes, "a", scalesim.ZoneLimits{Max: 3}))
+	assert.Equal(t, int32(2), ZoneSize(nodes, "b", scalesim.ZoneLimits{Min: 2, Max: 3}))
+}
+
+func TestMachineDeploymentNodeGroup(t *testing.T) {
+	shoot := &v1beta1.Shoot{Spec: v1beta1.ShootSpec{Provider: v1beta1.Provider{Workers: []v1beta1.Worker{
+		{Name: "p1", Zones: []string{"eu-west-1a", "eu-west-1b"}},
+	}}}}
+	mcd := &machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "shoot--dev--s-p1-z2"}}
+	pool, zone, ok := MachineDeploymentNodeGroup(shoot, mcd)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"p1", "eu-west-1b"}, []string{pool, zone})
+
+	mcd.Spec.Template.Spec.NodeTemplateSpec.Labels = map[string]string{
+		"worker.gardener.cloud/pool":    "p2",
+		"topology.ebs.csi.aws.com/zone": "eu-west-1c",
+	}
+	pool, zone, ok = MachineDeploymentNodeGroup(shoot, mcd)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"p2", "eu-west-1c"}, []string{pool, zone})
+
+	_, _, ok = MachineDeploymentNodeGroup(shoot, &machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "other"}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,125 @@
+package virtualcluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+// Fork copies the nodes, pods, PriorityClasses and storage objects of the virtual cluster into a new in-memory virtual
+// cluster with its own scheduler. Forking is cheap regardless of the backend of the source cluster since no
+// kube-apiserver or etcd is launched.
+func (a *access) Fork(ctx context.Context) (scalesim.VirtualClusterAccess, error) {
+	nodes, err := a.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := a.ListPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var objs []runtime.Object
+	for _, list := range []client.ObjectList{&schedulingv1.PriorityClassList{}, &storagev1.StorageClassList{}, &corev1.PersistentVolumeList{}, &corev1.PersistentVolumeClaimList{}} {
+		if err = a.client.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("cannot list %T: %w", list, err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, items...)
+	}
+	fork, err := initializeInMemoryAccess(a.client.Scheme())
+	if err != nil {
+		return nil, fmt.Errorf("cannot fork virtual cluster: %w", err)
+	}
+	fork.referenceNodes = maps.Clone(a.referenceNodes)
+	for _, node := range nodes {
+		if err = fork.client.Create(ctx, cloneForCopy(&node)); err != nil {
+			fork.Shutdown()
+			return nil, fmt.Errorf("cannot copy node %q into fork: %w", node.Name, err)
+		}
+	}
+	for _, obj := range objs {
+		if err = fork.client.Create(ctx, cloneForCopy(obj.(client.Object))); err != nil {
+			fork.Shutdown()
+			return nil, fmt.Errorf("cannot copy %T %s into fork: %w", obj, client.ObjectKeyFromObject(obj.(client.Object)), err)
+		}
+	}
+	for _, pod := range pods {
+		if err = fork.client.Create(ctx, cloneForCopy(&pod)); err != nil {
+			fork.Shutdown()
+			return nil, fmt.Errorf("cannot copy pod %s into fork: %w", client.ObjectKeyFromObject(&pod), err)
+		}
+	}
+	slog.Info("forked virtual cluster", "nodes", len(nodes), "pods", len(pods))
+	return fork, nil
+}
+
+// Merge creates the nodes and bound pods of the fork that are missing in the virtual cluster. Pods that are still
+// unscheduled in the fork are not merged.
+func (a *access) Merge(ctx context.Context, fork scalesim.VirtualClusterAccess) error {
+	nodes, err := a.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	nodeNames := sets.New[string]()
+	for _, node := range nodes {
+		nodeNames.Insert(node.Name)
+	}
+	pods, err := a.ListPods(ctx)
+	if err != nil {
+		return err
+	}
+	podKeys := sets.New[client.ObjectKey]()
+	for _, pod := range pods {
+		podKeys.Insert(client.ObjectKeyFromObject(&pod))
+	}
+
+	forkNodes, err := fork.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range forkNodes {
+		if nodeNames.Has(node.Name) {
+			continue
+		}
+		if err = a.client.Create(ctx, cloneForCopy(&node)); err != nil {
+			return fmt.Errorf("cannot merge node %q from fork: %w", node.Name, err)
+		}
+	}
+	forkPods, err := fork.ListPods(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pod := range forkPods {
+		if pod.Spec.NodeName == "" || podKeys.Has(client.ObjectKeyFromObject(&pod)) {
+			continue
+		}
+		if err = a.client.Create(ctx, cloneForCopy(&pod)); err != nil {
+			return fmt.Errorf("cannot merge pod %s from fork: %w", client.ObjectKeyFromObject(&pod), err)
+		}
+	}
+	return nil
+}
+
+// cloneForCopy returns a copy of the object without the fields that are set by the API server on creation.
+func cloneForCopy[T client.Object](obj T) T {
+	clone := obj.DeepCopyObject().(T)
+	clone.SetUID("")
+	clone.SetResourceVersion("")
+	clone.SetCreationTimestamp(metav1.Time{})
+	return clone
+}
@@ -0,0 +1,42 @@
+package virtualcluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestForkAndMerge(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	assert.Nil(t, vca.AddNodes(ctx, newTestNode("node-a", "1")))
+
+	fork, err := vca.Fork(ctx)
+	assert.Nil(t, err)
+	defer fork.Shutdown()
+	assert.Nil(t, fork.AddNodes(ctx, newTestNode("node-b", "2")))
+	results, err := fork.SchedulePods(ctx, "", newTestPod("pod-a", "1500m"), newTestPod("pod-b", "1500m"))
+	assert.Nil(t, err)
+	assert.Equal(t, "node-b", results[0].NodeName)
+	assert.False(t, results[1].IsScheduled())
+
+	nodes, err := vca.ListNodes(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1, "changes to the fork must not affect the virtual cluster")
+
+	assert.Nil(t, vca.Merge(ctx, fork))
+	nodes, err = vca.ListNodes(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 2)
+	pods, err := vca.ListPods(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, pods, 1, "unscheduled pods of the fork must not be merged")
+	pod, err := vca.GetPod(ctx, types.NamespacedName{Name: "pod-a", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Equal(t, "node-b", pod.Spec.NodeName)
+}
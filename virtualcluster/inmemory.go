@@ -0,0 +1,247 @@
+package virtualcluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newInMemoryClient creates a client.WithWatch backed by an in-memory object tracker. Watches on this client
+// are served directly from the tracker so that a scheduler can be driven without a kube-apiserver or etcd.
+func newInMemoryClient(scheme *runtime.Scheme) *clusterScopedClient {
+	return &clusterScopedClient{
+		WithWatch: fake.NewClientBuilder().WithScheme(scheme).Build(),
+	}
+}
+
+// clusterScopedClient mimics the kube-apiserver behaviour of ignoring the namespace for cluster-scoped objects.
+// Callers like to set a "default" namespace on nodes which the in-memory object tracker would otherwise honour.
+// Listeners are notified synchronously of every successful change, which unlike the buffered tracker watch
+// cannot overflow when thousands of pods are created in a burst.
+type clusterScopedClient struct {
+	client.WithWatch
+	mu        sync.RWMutex
+	listeners []listener
+}
+
+// listener is notified of a change of the in-memory client with the context of the call that made the change.
+type listener func(ctx context.Context, eventType watch.EventType, obj runtime.Object)
+
+func (c *clusterScopedClient) addListener(listener listener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+func (c *clusterScopedClient) notify(ctx context.Context, eventType watch.EventType, obj runtime.Object, err error) error {
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, listener := range c.listeners {
+		listener(ctx, eventType, obj)
+	}
+	return nil
+}
+
+func isClusterScoped(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *corev1.Node, *corev1.NodeList,
+		*corev1.Namespace, *corev1.NamespaceList,
+		*corev1.PersistentVolume, *corev1.PersistentVolumeList,
+		*storagev1.StorageClass, *storagev1.StorageClassList,
+		*schedulingv1.PriorityClass, *schedulingv1.PriorityClassList:
+		return true
+	}
+	return false
+}
+
+func (c *clusterScopedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if isClusterScoped(obj) {
+		key.Namespace = ""
+	}
+	return c.WithWatch.Get(ctx, key, obj, opts...)
+}
+
+func (c *clusterScopedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if isClusterScoped(obj) {
+		obj.SetNamespace("")
+	}
+	if obj.GetUID() == "" {
+		obj.SetUID(uuid.NewUUID())
+	}
+	if creationTimestamp := obj.GetCreationTimestamp(); creationTimestamp.IsZero() {
+		obj.SetCreationTimestamp(metav1.Now())
+	}
+	return c.notify(ctx, watch.Added, obj, c.WithWatch.Create(ctx, obj, opts...))
+}
+
+func (c *clusterScopedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if isClusterScoped(obj) {
+		obj.SetNamespace("")
+	}
+	return c.notify(ctx, watch.Modified, obj, c.WithWatch.Update(ctx, obj, opts...))
+}
+
+func (c *clusterScopedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if isClusterScoped(obj) {
+		obj.SetNamespace("")
+	}
+	return c.notify(ctx, watch.Modified, obj, c.WithWatch.Patch(ctx, obj, patch, opts...))
+}
+
+func (c *clusterScopedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if isClusterScoped(obj) {
+		obj.SetNamespace("")
+	}
+	return c.notify(ctx, watch.Deleted, obj, c.WithWatch.Delete(ctx, obj, opts...))
+}
+
+// DeleteAllOf deletes the matching objects one by one, so that the listeners are notified of every deleted object.
+func (c *clusterScopedClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	deleteAllOfOpts := &client.DeleteAllOfOptions{}
+	deleteAllOfOpts.ApplyOptions(opts)
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	listObj, err := c.Scheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return err
+	}
+	list, ok := listObj.(client.ObjectList)
+	if !ok {
+		return fmt.Errorf("%s is not a list", gvk.Kind+"List")
+	}
+	if err = c.List(ctx, list, &deleteAllOfOpts.ListOptions); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err = c.Delete(ctx, item.(client.Object), &deleteAllOfOpts.DeleteOptions); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clusterScopedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if !isClusterScoped(list) {
+		return c.WithWatch.List(ctx, list, opts...)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	listOpts.Namespace = ""
+	return c.WithWatch.List(ctx, list, listOpts)
+}
+
+// clearAllInMemory is the in-memory equivalent of `kubectl delete all --all` followed by ClearNodes. Objects are
+// removed synchronously from the tracker, so unlike the envtest backend there is no need to wait for deletion.
+func (a *access) clearAllInMemory(ctx context.Context) error {
+	namespaces, err := a.listPodNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		if err = a.client.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("cannot delete pods in namespace %q: %w", ns, err)
+		}
+		if err = a.client.DeleteAllOf(ctx, &corev1.Event{}, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("cannot delete events in namespace %q: %w", ns, err)
+		}
+	}
+	if err = a.client.DeleteAllOf(ctx, &corev1.Node{}); err != nil {
+		return fmt.Errorf("cannot delete nodes: %w", err)
+	}
+	slog.Info("cleared all objects from in-memory virtual cluster")
+	return nil
+}
+
+func (a *access) listPodNamespaces(ctx context.Context) ([]string, error) {
+	podList := corev1.PodList{}
+	if err := a.client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("cannot list pods: %w", err)
+	}
+	namespaces := []string{"default"}
+	seen := map[string]struct{}{"default": {}}
+	for _, pod := range podList.Items {
+		if _, ok := seen[pod.Namespace]; ok {
+			continue
+		}
+		seen[pod.Namespace] = struct{}{}
+		namespaces = append(namespaces, pod.Namespace)
+	}
+	return namespaces, nil
+}
+
+// mirrorStorageObjects returns a listener that copies the storage objects of the virtual cluster into the clientSet
+// whose informers feed the volume plugins of the in-process kube-scheduler. The listener waits until the informer
+// has observed the change, so that it is visible to the next scheduling cycle, but not longer than the context of the
+// change allows.
+func mirrorStorageObjects(clientSet *fakeclientset.Clientset, informerFactory informers.SharedInformerFactory) listener {
+	tracker := clientSet.Tracker()
+	return func(ctx context.Context, eventType watch.EventType, obj runtime.Object) {
+		var resource schema.GroupVersionResource
+		var informer cache.SharedIndexInformer
+		switch obj.(type) {
+		case *corev1.PersistentVolume:
+			resource = corev1.SchemeGroupVersion.WithResource("persistentvolumes")
+			informer = informerFactory.Core().V1().PersistentVolumes().Informer()
+		case *corev1.PersistentVolumeClaim:
+			resource = corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims")
+			informer = informerFactory.Core().V1().PersistentVolumeClaims().Informer()
+		case *storagev1.StorageClass:
+			resource = storagev1.SchemeGroupVersion.WithResource("storageclasses")
+			informer = informerFactory.Storage().V1().StorageClasses().Informer()
+		default:
+			return
+		}
+		o := obj.(client.Object)
+		deleted := eventType == watch.Deleted
+		var err error
+		switch eventType {
+		case watch.Deleted:
+			err = tracker.Delete(resource, o.GetNamespace(), o.GetName())
+		case watch.Modified:
+			err = tracker.Update(resource, o.DeepCopyObject(), o.GetNamespace())
+		default:
+			err = tracker.Add(o.DeepCopyObject())
+		}
+		if err == nil {
+			key, _ := cache.MetaNamespaceKeyFunc(o)
+			err = wait.PollUntilContextTimeout(ctx, 5*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+				item, exists, err := informer.GetStore().GetByKey(key)
+				if err != nil || deleted {
+					return !exists, err
+				}
+				return exists && item.(client.Object).GetResourceVersion() == o.GetResourceVersion(), nil
+			})
+		}
+		if err != nil {
+			slog.Warn("cannot mirror storage object to scheduler", "resource", resource.Resource, "name", o.GetName(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package virtualcluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestInMemoryAccessNodesIgnoreNamespace(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-a",
+			Namespace: "default",
+			Labels:    map[string]string{"worker.gardener.cloud/pool": "p1"},
+		},
+	}
+	assert.Nil(t, vca.AddNodes(ctx, node))
+
+	got, err := vca.GetNode(ctx, types.NamespacedName{Name: "node-a", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Equal(t, "node-a", got.Name)
+
+	poolNodes, err := vca.ListNodesInNodePool(ctx, "p1")
+	assert.Nil(t, err)
+	assert.Len(t, poolNodes, 1)
+
+	assert.Nil(t, vca.DeleteNodesWithMatchingLabels(ctx, map[string]string{"worker.gardener.cloud/pool": "p1"}))
+	nodes, err := vca.ListNodes(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, nodes)
+}
+
+func TestInMemoryAccessClearAll(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+
+	assert.Nil(t, vca.AddNodes(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}))
+	assert.Nil(t, vca.AddPods(ctx,
+		corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Namespace: "default"}},
+		corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-b", Namespace: "kube-system"}},
+	))
+
+	assert.Nil(t, vca.ClearAll(ctx))
+
+	pods, err := vca.ListPods(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, pods)
+	nodes, err := vca.ListNodes(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, nodes)
+	assert.Empty(t, vca.KubeConfigPath())
+}
+
+func TestInMemoryAccessSchedulesPods(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+
+	assert.Nil(t, vca.AddNodes(ctx, newTestNode("node-a", "2")))
+	assert.Nil(t, vca.AddPods(ctx, newTestPod("pod-fits", "1"), newTestPod("pod-too-big", "3")))
+
+	assert.Eventually(t, func() bool {
+		pod, err := vca.GetPod(ctx, types.NamespacedName{Name: "pod-fits", Namespace: "default"})
+		return err == nil && pod.Spec.NodeName == "node-a"
+	}, 5*time.Second, 50*time.Millisecond)
+	pod, err := vca.GetPod(ctx, types.NamespacedName{Name: "pod-too-big", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Empty(t, pod.Spec.NodeName)
+}
+
+func TestInMemoryAccessMirrorsStorageObjectChanges(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	a := vca.(*access)
+	pvcLister := a.scheduler.informerFactory.Core().V1().PersistentVolumeClaims().Lister()
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}}
+	assert.Nil(t, a.client.Create(ctx, pvc))
+	pvc.Spec.VolumeName = "pv-a"
+	assert.Nil(t, a.client.Update(ctx, pvc))
+	mirrored, err := pvcLister.PersistentVolumeClaims("default").Get("data")
+	assert.Nil(t, err)
+	assert.Equal(t, "pv-a", mirrored.Spec.VolumeName)
+
+	assert.Nil(t, a.client.DeleteAllOf(ctx, &corev1.PersistentVolumeClaim{}, client.InNamespace("default")))
+	claims, err := pvcLister.List(labels.Everything())
+	assert.Nil(t, err)
+	assert.Empty(t, claims)
+}
@@ -0,0 +1,602 @@
+package virtualcluster
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	schedulerscheme "k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkplugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalesim "github.com/elankath/scaler-simulator"
+)
+
+//go:embed scheduler-config.yaml
+var schedulerConfigYAML []byte
+
+const defaultSchedulerName = "default-scheduler"
+
+// scheduler runs the kube-scheduler framework in-process against the objects of the virtual cluster. Every scheduling
+// cycle works on a fresh snapshot of nodes and bound pods and evaluates pending pods one after another in queue order.
+// As the virtual cluster has no PV controller or provisioner, the pre-bind plugins are skipped for pods with unbound
+// PersistentVolumeClaims: such pods are bound as if their volumes were provisioned for the chosen node.
+type scheduler struct {
+	client          client.WithWatch
+	backend         Backend
+	frameworks      map[string]framework.Framework
+	informerFactory informers.SharedInformerFactory
+	snapshot        *snapshot
+	// mu serializes scheduling cycles.
+	mu sync.Mutex
+	// queueMu guards the fields below which are updated from object notifications.
+	queueMu sync.Mutex
+	// queueSeq records the order in which pending pods were first seen, keyed by namespace/name.
+	queueSeq map[string]int64
+	nextSeq  int64
+	// unschedulable holds pods that failed scheduling and are not retried until the cluster changes.
+	unschedulable sets.Set[string]
+	trigger       chan struct{}
+}
+
+func newScheduler(ctx context.Context, c client.WithWatch, clientSet kubernetes.Interface, backend Backend) (*scheduler, error) {
+	schedulerConfig, err := loadSchedulerConfig()
+	if err != nil {
+		return nil, err
+	}
+	s := &scheduler{
+		client:          c,
+		backend:         backend,
+		frameworks:      make(map[string]framework.Framework, len(schedulerConfig.Profiles)),
+		informerFactory: informers.NewSharedInformerFactory(clientSet, 0),
+		snapshot:        newSnapshot(),
+		queueSeq:        make(map[string]int64),
+		unschedulable:   sets.New[string](),
+		trigger:         make(chan struct{}, 1),
+	}
+	registry := frameworkplugins.NewInTreeRegistry()
+	for i := range schedulerConfig.Profiles {
+		profile := &schedulerConfig.Profiles[i]
+		fwk, err := frameworkruntime.NewFramework(ctx, registry, profile,
+			frameworkruntime.WithClientSet(clientSet),
+			frameworkruntime.WithInformerFactory(s.informerFactory),
+			frameworkruntime.WithSnapshotSharedLister(s.snapshot),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create scheduler framework for profile %q: %w", profile.SchedulerName, err)
+		}
+		s.frameworks[profile.SchedulerName] = fwk
+	}
+	s.informerFactory.Start(ctx.Done())
+	s.informerFactory.WaitForCacheSync(ctx.Done())
+	return s, nil
+}
+
+func loadSchedulerConfig() (*config.KubeSchedulerConfiguration, error) {
+	obj, _, err := schedulerscheme.Codecs.UniversalDecoder().Decode(schedulerConfigYAML, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode scheduler config: %w", err)
+	}
+	schedulerConfig, ok := obj.(*config.KubeSchedulerConfiguration)
+	if !ok {
+		return nil, fmt.Errorf("unexpected scheduler config type %T", obj)
+	}
+	return schedulerConfig, nil
+}
+
+// run schedules pending pods whenever the virtual cluster changes until the context is cancelled.
+func (s *scheduler) run(ctx context.Context) {
+	slog.Info("started in-process kube-scheduler", "profiles", len(s.frameworks))
+	s.signal()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("stopped in-process kube-scheduler")
+			return
+		case <-s.trigger:
+			if _, err := s.schedulePending(ctx); err != nil {
+				slog.Error("scheduling cycle failed", "error", err)
+			}
+		}
+	}
+}
+
+// watchObjects feeds the scheduler with watch events of nodes and pods from the API server.
+func (s *scheduler) watchObjects(ctx context.Context) {
+	go s.watch(ctx, &corev1.PodList{})
+	go s.watch(ctx, &corev1.NodeList{})
+}
+
+func (s *scheduler) watch(ctx context.Context, list client.ObjectList) {
+	for ctx.Err() == nil {
+		w, err := s.client.Watch(ctx, list)
+		if err != nil {
+			slog.Warn("cannot watch objects, retrying", "type", fmt.Sprintf("%T", list), "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		s.consume(ctx, w)
+	}
+}
+
+func (s *scheduler) consume(ctx context.Context, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			s.notify(event.Type, event.Object)
+		}
+	}
+}
+
+// notify is called for every change of a node or pod in the virtual cluster.
+func (s *scheduler) notify(eventType watch.EventType, obj runtime.Object) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	switch o := obj.(type) {
+	case *corev1.Node:
+		s.unschedulable.Clear()
+	case *corev1.Pod:
+		key := client.ObjectKeyFromObject(o).String()
+		switch {
+		case eventType == watch.Deleted:
+			delete(s.queueSeq, key)
+			s.unschedulable.Clear()
+		case o.Spec.NodeName != "":
+			delete(s.queueSeq, key)
+			return
+		default:
+			s.enqueue(key)
+			s.unschedulable.Delete(key)
+		}
+	default:
+		return
+	}
+	s.signal()
+}
+
+func (s *scheduler) enqueue(key string) {
+	if _, ok := s.queueSeq[key]; !ok {
+		s.nextSeq++
+		s.queueSeq[key] = s.nextSeq
+	}
+}
+
+func (s *scheduler) signal() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// schedulePending runs a scheduling cycle for pending pods that have not failed scheduling since the last cluster change.
+func (s *scheduler) schedulePending(ctx context.Context) ([]scalesim.PodSchedulingResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scheduleCycle(ctx, nil)
+}
+
+// schedulePods creates the given pods and schedules exactly those pods in a single cycle. The scheduling lock is held
+// while the pods are created so that the background loop cannot pick them up first, which makes the returned result
+// for each pod definitive. Results are returned in the order of the given pods.
+func (s *scheduler) schedulePods(ctx context.Context, pods []*corev1.Pod) ([]scalesim.PodSchedulingResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	podKeys := sets.New[string]()
+	for _, pod := range pods {
+		if err := s.client.Create(ctx, pod); err != nil {
+			return nil, fmt.Errorf("cannot create pod %s: %w", client.ObjectKeyFromObject(pod), err)
+		}
+		key := client.ObjectKeyFromObject(pod).String()
+		s.queueMu.Lock()
+		s.enqueue(key)
+		s.queueMu.Unlock()
+		podKeys.Insert(key)
+	}
+	results, err := s.scheduleCycle(ctx, podKeys)
+	if err != nil {
+		return nil, err
+	}
+	resultsByPod := make(map[string]scalesim.PodSchedulingResult, len(results))
+	for _, result := range results {
+		resultsByPod[result.Pod.String()] = result
+	}
+	orderedResults := make([]scalesim.PodSchedulingResult, 0, len(pods))
+	for _, pod := range pods {
+		key := client.ObjectKeyFromObject(pod)
+		result, ok := resultsByPod[key.String()]
+		if !ok {
+			result = scalesim.PodSchedulingResult{
+				Pod:     key,
+				Message: fmt.Sprintf("no scheduler profile %q to schedule pod", schedulerNameOf(pod)),
+			}
+		}
+		orderedResults = append(orderedResults, result)
+	}
+	return orderedResults, nil
+}
+
+// scheduleCycle schedules pending pods against a fresh snapshot. If onlyPods is not nil, only those pods are
+// considered and pods that previously failed scheduling are retried. The caller must hold s.mu.
+func (s *scheduler) scheduleCycle(ctx context.Context, onlyPods sets.Set[string]) ([]scalesim.PodSchedulingResult, error) {
+	nodeList := corev1.NodeList{}
+	if err := s.client.List(ctx, &nodeList); err != nil {
+		return nil, fmt.Errorf("cannot list nodes: %w", err)
+	}
+	podList := corev1.PodList{}
+	if err := s.client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("cannot list pods: %w", err)
+	}
+	pendingPods := s.pendingPods(podList.Items, onlyPods)
+	if len(pendingPods) == 0 {
+		return nil, nil
+	}
+	s.snapshot.reset(nodeList.Items, podList.Items)
+	results := make([]scalesim.PodSchedulingResult, 0, len(pendingPods))
+	for _, pod := range pendingPods {
+		result, err := s.scheduleOne(ctx, pod)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// pendingPods returns the unbound pods handled by one of the scheduler profiles ordered like the PrioritySort queue.
+func (s *scheduler) pendingPods(pods []corev1.Pod, onlyPods sets.Set[string]) []*corev1.Pod {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	var pendingPods []*corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := s.frameworks[schedulerNameOf(pod)]; !ok {
+			continue
+		}
+		key := client.ObjectKeyFromObject(pod).String()
+		if onlyPods != nil {
+			if !onlyPods.Has(key) {
+				continue
+			}
+		} else if s.unschedulable.Has(key) {
+			continue
+		}
+		s.enqueue(key)
+		pendingPods = append(pendingPods, pod)
+	}
+	slices.SortStableFunc(pendingPods, func(a, b *corev1.Pod) int {
+		if c := cmp.Compare(podPriority(b), podPriority(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(s.queueSeq[client.ObjectKeyFromObject(a).String()], s.queueSeq[client.ObjectKeyFromObject(b).String()])
+	})
+	return pendingPods
+}
+
+// scheduleOne runs the filter and score plugins of the pod's profile against the snapshot and binds the pod to the
+// node with the highest score. Ties are broken by node name so that results are deterministic. The node is reserved
+// and the permit and pre-bind plugins run before the binding, which reverts the reservation if one of them fails.
+func (s *scheduler) scheduleOne(ctx context.Context, pod *corev1.Pod) (scalesim.PodSchedulingResult, error) {
+	fwk := s.frameworks[schedulerNameOf(pod)]
+	key := client.ObjectKeyFromObject(pod)
+	result := scalesim.PodSchedulingResult{Pod: key}
+	state := framework.NewCycleState()
+
+	preFilterResult, status := fwk.RunPreFilterPlugins(ctx, state, pod)
+	if !status.IsSuccess() {
+		result.PreFilterFailure = &scalesim.PluginStatus{Plugin: status.Plugin(), Reasons: status.Reasons()}
+		result.Message = fmt.Sprintf("0/%d nodes are available: %s.", len(s.snapshot.nodeInfos), strings.Join(status.Reasons(), ", "))
+		return result, s.markUnschedulable(ctx, pod, result)
+	}
+
+	var feasibleNodes []*corev1.Node
+	for _, nodeInfo := range s.snapshot.nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		if !preFilterResult.AllNodes() && !preFilterResult.NodeNames.Has(nodeName) {
+			continue
+		}
+		status = fwk.RunFilterPlugins(ctx, state, pod, nodeInfo)
+		if status.IsSuccess() {
+			feasibleNodes = append(feasibleNodes, nodeInfo.Node())
+			continue
+		}
+		if result.FilterFailures == nil {
+			result.FilterFailures = make(map[string]scalesim.PluginStatus)
+		}
+		result.FilterFailures[nodeName] = scalesim.PluginStatus{Plugin: status.Plugin(), Reasons: status.Reasons()}
+	}
+	if len(feasibleNodes) == 0 {
+		result.Message = fitErrorMessage(len(s.snapshot.nodeInfos), result.FilterFailures)
+		return result, s.markUnschedulable(ctx, pod, result)
+	}
+
+	if status = fwk.RunPreScorePlugins(ctx, state, pod, feasibleNodes); !status.IsSuccess() {
+		return result, fmt.Errorf("pre-score plugin %q failed for pod %s: %w", status.Plugin(), key, status.AsError())
+	}
+	nodePluginScores, status := fwk.RunScorePlugins(ctx, state, pod, feasibleNodes)
+	if !status.IsSuccess() {
+		return result, fmt.Errorf("score plugin %q failed for pod %s: %w", status.Plugin(), key, status.AsError())
+	}
+	result.NodeScores = make([]scalesim.NodeScore, 0, len(nodePluginScores))
+	for _, nps := range nodePluginScores {
+		pluginScores := make(map[string]int64, len(nps.Scores))
+		for _, ps := range nps.Scores {
+			pluginScores[ps.Name] = ps.Score
+		}
+		result.NodeScores = append(result.NodeScores, scalesim.NodeScore{NodeName: nps.Name, TotalScore: nps.TotalScore, PluginScores: pluginScores})
+	}
+	slices.SortFunc(result.NodeScores, func(a, b scalesim.NodeScore) int {
+		if c := cmp.Compare(b.TotalScore, a.TotalScore); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.NodeName, b.NodeName)
+	})
+	result.NodeName = result.NodeScores[0].NodeName
+
+	if status = fwk.RunReservePluginsReserve(ctx, state, pod, result.NodeName); !status.IsSuccess() {
+		return s.unreserve(ctx, fwk, state, pod, result, "reserve", status)
+	}
+	status = fwk.RunPermitPlugins(ctx, state, pod, result.NodeName)
+	if status.IsWait() {
+		status = fwk.WaitOnPermit(ctx, pod)
+	}
+	if !status.IsSuccess() {
+		return s.unreserve(ctx, fwk, state, pod, result, "permit", status)
+	}
+	// there is no PV controller or provisioner that would complete the binding of unbound claims, so the volumes are
+	// left to be provisioned for the chosen node like it would happen in the shoot.
+	if !s.hasUnboundClaims(pod) {
+		if status = fwk.RunPreBindPlugins(ctx, state, pod, result.NodeName); !status.IsSuccess() {
+			return s.unreserve(ctx, fwk, state, pod, result, "pre-bind", status)
+		}
+	}
+	if err := s.bind(ctx, pod, result.NodeName); err != nil {
+		fwk.RunReservePluginsUnreserve(ctx, state, pod, result.NodeName)
+		return result, err
+	}
+	fwk.RunPostBindPlugins(ctx, state, pod, result.NodeName)
+	result.Message = fmt.Sprintf("Successfully assigned %s to %s", key, result.NodeName)
+	return result, s.recordEvent(ctx, pod, corev1.EventTypeNormal, "Scheduled", "Binding", result.Message)
+}
+
+// unreserve reverts the reservation of the chosen node for the pod after a failed binding cycle. The pod is marked
+// unschedulable if a plugin rejected the node, other failures are returned as errors.
+func (s *scheduler) unreserve(ctx context.Context, fwk framework.Framework, state *framework.CycleState, pod *corev1.Pod, result scalesim.PodSchedulingResult, extensionPoint string, status *framework.Status) (scalesim.PodSchedulingResult, error) {
+	fwk.RunReservePluginsUnreserve(ctx, state, pod, result.NodeName)
+	if !status.IsRejected() {
+		return result, fmt.Errorf("%s plugin %q failed for pod %s on node %s: %w", extensionPoint, status.Plugin(), result.Pod, result.NodeName, status.AsError())
+	}
+	result.Message = fmt.Sprintf("%s plugin %q rejected node %s: %s.", extensionPoint, status.Plugin(), result.NodeName, strings.Join(status.Reasons(), ", "))
+	result.NodeName = ""
+	return result, s.markUnschedulable(ctx, pod, result)
+}
+
+// hasUnboundClaims returns true if one of the PersistentVolumeClaims of the pod, including the ones of generic
+// ephemeral volumes, is not bound to a PersistentVolume yet.
+func (s *scheduler) hasUnboundClaims(pod *corev1.Pod) bool {
+	pvcLister := s.informerFactory.Core().V1().PersistentVolumeClaims().Lister()
+	for _, volume := range pod.Spec.Volumes {
+		var claimName string
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claimName = volume.PersistentVolumeClaim.ClaimName
+		case volume.Ephemeral != nil:
+			claimName = pod.Name + "-" + volume.Name
+		default:
+			continue
+		}
+		pvc, err := pvcLister.PersistentVolumeClaims(pod.Namespace).Get(claimName)
+		if err != nil || pvc.Spec.VolumeName == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scheduler) bind(ctx context.Context, pod *corev1.Pod, nodeName string) error {
+	if s.backend == InMemoryBackend {
+		boundPod := pod.DeepCopy()
+		boundPod.Spec.NodeName = nodeName
+		if err := s.client.Update(ctx, boundPod); err != nil {
+			return fmt.Errorf("cannot bind pod %s to node %s: %w", client.ObjectKeyFromObject(pod), nodeName, err)
+		}
+		pod = boundPod
+	} else {
+		binding := &corev1.Binding{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID},
+			Target:     corev1.ObjectReference{Kind: "Node", Name: nodeName},
+		}
+		if err := s.client.SubResource("binding").Create(ctx, pod, binding); err != nil {
+			return fmt.Errorf("cannot bind pod %s to node %s: %w", client.ObjectKeyFromObject(pod), nodeName, err)
+		}
+		pod = pod.DeepCopy()
+		pod.Spec.NodeName = nodeName
+	}
+	return s.snapshot.addPod(pod)
+}
+
+func (s *scheduler) markUnschedulable(ctx context.Context, pod *corev1.Pod, result scalesim.PodSchedulingResult) error {
+	s.queueMu.Lock()
+	s.unschedulable.Insert(result.Pod.String())
+	s.queueMu.Unlock()
+	return s.recordEvent(ctx, pod, corev1.EventTypeWarning, "FailedScheduling", "Scheduling", result.Message)
+}
+
+func (s *scheduler) recordEvent(ctx context.Context, pod *corev1.Pod, eventType, reason, action, message string) error {
+	now := time.Now()
+	schedulerName := schedulerNameOf(pod)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name + "." + strconv.FormatInt(now.UnixNano(), 16),
+			Namespace: pod.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			UID:        pod.UID,
+		},
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Action:              action,
+		EventTime:           metav1.NewMicroTime(now),
+		Source:              corev1.EventSource{Component: schedulerName},
+		ReportingController: schedulerName,
+		ReportingInstance:   schedulerName,
+	}
+	if err := s.client.Create(ctx, event); err != nil {
+		return fmt.Errorf("cannot record %s event for pod %s: %w", reason, client.ObjectKeyFromObject(pod), err)
+	}
+	return nil
+}
+
+// fitErrorMessage builds a message in the format of the kube-scheduler FitError.
+func fitErrorMessage(numNodes int, filterFailures map[string]scalesim.PluginStatus) string {
+	reasonCounts := make(map[string]int)
+	for _, status := range filterFailures {
+		for _, reason := range status.Reasons {
+			reasonCounts[reason]++
+		}
+	}
+	reasons := make([]string, 0, len(reasonCounts))
+	for reason, count := range reasonCounts {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	slices.Sort(reasons)
+	return fmt.Sprintf("0/%d nodes are available: %s.", numNodes, strings.Join(reasons, ", "))
+}
+
+func schedulerNameOf(pod *corev1.Pod) string {
+	if pod.Spec.SchedulerName == "" {
+		return defaultSchedulerName
+	}
+	return pod.Spec.SchedulerName
+}
+
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// snapshot implements framework.SharedLister over the nodes and bound pods of the virtual cluster.
+type snapshot struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+var _ framework.SharedLister = (*snapshot)(nil)
+
+func newSnapshot() *snapshot {
+	return &snapshot{nodeInfoMap: make(map[string]*framework.NodeInfo)}
+}
+
+func (s *snapshot) reset(nodes []corev1.Node, pods []corev1.Pod) {
+	s.nodeInfos = make([]*framework.NodeInfo, 0, len(nodes))
+	s.nodeInfoMap = make(map[string]*framework.NodeInfo, len(nodes))
+	for i := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(&nodes[i])
+		s.nodeInfos = append(s.nodeInfos, nodeInfo)
+		s.nodeInfoMap[nodes[i].Name] = nodeInfo
+	}
+	slices.SortFunc(s.nodeInfos, func(a, b *framework.NodeInfo) int {
+		return cmp.Compare(a.Node().Name, b.Node().Name)
+	})
+	for i := range pods {
+		if pods[i].Spec.NodeName == "" {
+			continue
+		}
+		if nodeInfo, ok := s.nodeInfoMap[pods[i].Spec.NodeName]; ok {
+			nodeInfo.AddPod(&pods[i])
+		}
+	}
+}
+
+func (s *snapshot) addPod(pod *corev1.Pod) error {
+	nodeInfo, ok := s.nodeInfoMap[pod.Spec.NodeName]
+	if !ok {
+		return fmt.Errorf("node %s of pod %s not found in snapshot", pod.Spec.NodeName, client.ObjectKeyFromObject(pod))
+	}
+	nodeInfo.AddPod(pod)
+	return nil
+}
+
+func (s *snapshot) NodeInfos() framework.NodeInfoLister {
+	return s
+}
+
+func (s *snapshot) StorageInfos() framework.StorageInfoLister {
+	return s
+}
+
+func (s *snapshot) List() ([]*framework.NodeInfo, error) {
+	return s.nodeInfos, nil
+}
+
+func (s *snapshot) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range s.nodeInfos {
+		if len(nodeInfo.PodsWithAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (s *snapshot) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range s.nodeInfos {
+		if len(nodeInfo.PodsWithRequiredAntiAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (s *snapshot) Get(nodeName string) (*framework.NodeInfo, error) {
+	if nodeInfo, ok := s.nodeInfoMap[nodeName]; ok {
+		return nodeInfo, nil
+	}
+	return nil, fmt.Errorf("nodeinfo not found for node name %q", nodeName)
+}
+
+func (s *snapshot) IsPVCUsedByPods(key string) bool {
+	for _, nodeInfo := range s.nodeInfos {
+		if nodeInfo.PVCRefCounts[key] > 0 {
+			return true
+		}
+	}
+	return false
+}
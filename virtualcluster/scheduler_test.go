@@ -0,0 +1,137 @@
+package virtualcluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+)
+
+func newTestNode(name, cpu string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func newTestPod(name, cpu string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}}},
+	}
+}
+
+func TestSchedulePods(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	assert.Nil(t, vca.AddNodes(ctx, newTestNode("node-a", "2"), newTestNode("node-b", "2")))
+
+	results, err := vca.SchedulePods(ctx, "", newTestPod("pod-a", "1500m"), newTestPod("pod-b", "1500m"), newTestPod("pod-c", "1500m"))
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, "pod-a", results[0].Pod.Name)
+	assert.Equal(t, "node-a", results[0].NodeName)
+	assert.Equal(t, "node-b", results[1].NodeName)
+	assert.False(t, results[2].IsScheduled())
+	assert.Equal(t, "NodeResourcesFit", results[2].FilterFailures["node-a"].Plugin)
+	assert.Contains(t, results[2].FilterFailures["node-b"].Reasons, "Insufficient cpu")
+	assert.Equal(t, "0/2 nodes are available: 2 Insufficient cpu.", results[2].Message)
+}
+
+func TestSchedulePodsWithBoundVolume(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	nodeA, nodeB := newTestNode("node-a", "2"), newTestNode("node-b", "2")
+	nodeA.Labels = map[string]string{corev1.LabelTopologyZone: "zone-a"}
+	nodeB.Labels = map[string]string{corev1.LabelTopologyZone: "zone-b"}
+	assert.Nil(t, vca.AddNodes(ctx, nodeA, nodeB))
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:    corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			ClaimRef:    &corev1.ObjectReference{Namespace: "default", Name: "data"},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-b"}}},
+			}}}},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{Driver: "disk.csi.test", VolumeHandle: "vol-1"}},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "data",
+			Namespace:   "default",
+			Annotations: map[string]string{"pv.kubernetes.io/bind-completed": "yes"},
+			Finalizers:  []string{"kubernetes.io/pvc-protection"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+			VolumeName:  pv.Name,
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	assert.Nil(t, vca.ApplyK8sObject(ctx, pv, pvc))
+	assert.Nil(t, vca.ApplyK8sObject(ctx, pv, pvc), "applying existing objects again must replace them")
+
+	pod := newTestPod("pod-a", "100m")
+	pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+	}}}
+	results, err := vca.SchedulePods(ctx, "", pod)
+	assert.Nil(t, err)
+	assert.Equal(t, "node-b", results[0].NodeName, results[0].Message)
+}
+
+func TestSchedulePodsWithUnboundVolume(t *testing.T) {
+	ctx := context.Background()
+	vca, err := InitializeAccess(scheme.Scheme, InMemoryBackend, "", nil)
+	assert.Nil(t, err)
+	defer vca.Shutdown()
+	assert.Nil(t, vca.AddNodes(ctx, newTestNode("node-a", "2")))
+
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: "standard"},
+		Provisioner:       "disk.csi.test",
+		VolumeBindingMode: ptr.To(storagev1.VolumeBindingWaitForFirstConsumer),
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+			StorageClassName: ptr.To(storageClass.Name),
+		},
+	}
+	assert.Nil(t, vca.ApplyK8sObject(ctx, storageClass, pvc))
+
+	pod := newTestPod("pod-a", "100m")
+	pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+	}}}
+	results, err := vca.SchedulePods(ctx, "", pod)
+	assert.Nil(t, err)
+	assert.Equal(t, "node-a", results[0].NodeName, results[0].Message)
+}
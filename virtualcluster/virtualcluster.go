@@ -2,24 +2,31 @@ package virtualcluster
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"reflect"
 	"slices"
 	"sync"
-	"syscall"
 	"time"
 
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -27,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
 	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/resutil"
 	"github.com/elankath/scaler-simulator/serutil"
 )
 
@@ -34,13 +42,25 @@ var kubeConfigPath = "/tmp/scalesim-kubeconfig.yaml"
 
 const BinPackingSchedulerName = "bin-packing-scheduler"
 
+// Backend identifies the implementation that stores the objects of the virtual cluster.
+type Backend string
+
+const (
+	// EnvTestBackend launches a kube-apiserver and etcd using envtest.
+	EnvTestBackend Backend = "envtest"
+	// InMemoryBackend keeps all objects in an in-memory object store. No binaries are required.
+	InMemoryBackend Backend = "inmemory"
+)
+
 type access struct {
-	client               client.Client
-	restConfig           *rest.Config
-	environment          *envtest.Environment
-	kubeSchedulerProcess *os.Process
-	referenceNodes       map[string]corev1.Node
-	mu                   sync.Mutex
+	backend        Backend
+	client         client.WithWatch
+	restConfig     *rest.Config
+	environment    *envtest.Environment
+	scheduler      *scheduler
+	stopScheduler  context.CancelFunc
+	referenceNodes map[string]corev1.Node
+	mu             sync.Mutex
 }
 
 var _ scalesim.VirtualClusterAccess = (*access)(nil) // Verify that *T implements I.
@@ -94,67 +114,10 @@ func (a *access) DeleteNodesWithMatchingLabels(ctx context.Context, labels map[s
 
 func (a *access) CreatePods(ctx context.Context, podOrder string, pods ...corev1.Pod) error {
 	if podOrder == "desc" {
-		totalMemoryRequested := int64(0)
-		totalCPURequested := int64(0)
-		for _, pod := range pods {
-			for _, container := range pod.Spec.Containers {
-				containerResources, ok := container.Resources.Requests[corev1.ResourceMemory]
-				if ok {
-					totalMemoryRequested += containerResources.MilliValue()
-				}
-				containerResources, ok = container.Resources.Requests[corev1.ResourceCPU]
-				if ok {
-					totalCPURequested += containerResources.MilliValue()
-				}
-			}
-		}
-		//TODO: include other resources for sorting
-		if totalMemoryRequested != 0 && totalCPURequested != 0 {
-			slices.SortFunc(pods, func(i, j corev1.Pod) int {
-				//return -i.Spec.Containers[0].Resources.Requests.Memory().Cmp(*j.Spec.Containers[0].Resources.Requests.Memory())
-				podICPUSum := resource.Quantity{}
-				podIMemorySum := resource.Quantity{}
-				podJCPUSum := resource.Quantity{}
-				podJMemorySum := resource.Quantity{}
-
-				for _, container := range i.Spec.Containers {
-					if request, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-						podICPUSum.Add(request)
-					}
-					if request, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-						podIMemorySum.Add(request)
-					}
-				}
-
-				for _, container := range j.Spec.Containers {
-					if request, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-						podJCPUSum.Add(request)
-					}
-					if request, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-						podJMemorySum.Add(request)
-					}
-				}
-
-				podIScore := (float64(podIMemorySum.MilliValue()) / float64(totalMemoryRequested)) + (float64(podICPUSum.MilliValue()) / float64(totalCPURequested))
-				podJScore := (float64(podJMemorySum.MilliValue()) / float64(totalMemoryRequested)) + (float64(podJCPUSum.MilliValue()) / float64(totalCPURequested))
-
-				if podIScore < podJScore {
-					return 1
-				} else if podIScore > podJScore {
-					return -1
-				} else {
-					return 0
-				}
-			})
-		}
+		sortPodsByDescendingRequests(pods)
 	}
 	for _, pod := range pods {
-		clone := pod.DeepCopy()
-		clone.ObjectMeta.UID = ""
-		clone.ObjectMeta.ResourceVersion = ""
-		clone.ObjectMeta.CreationTimestamp = metav1.Time{}
-		clone.Spec.NodeName = ""
-		err := a.client.Create(ctx, clone)
+		err := a.client.Create(ctx, cloneForScheduling(&pod))
 		if err != nil {
 			slog.Error("Error creating the pod.", "error", err)
 			return err
@@ -163,6 +126,51 @@ func (a *access) CreatePods(ctx context.Context, podOrder string, pods ...corev1
 	return nil
 }
 
+func (a *access) SchedulePods(ctx context.Context, podOrder string, pods ...corev1.Pod) ([]scalesim.PodSchedulingResult, error) {
+	if podOrder == "desc" {
+		sortPodsByDescendingRequests(pods)
+	}
+	clones := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		clones = append(clones, cloneForScheduling(&pod))
+	}
+	return a.scheduler.schedulePods(ctx, clones)
+}
+
+// cloneForScheduling returns a copy of the pod that can be created in the virtual cluster as an unscheduled pod.
+func cloneForScheduling(pod *corev1.Pod) *corev1.Pod {
+	clone := pod.DeepCopy()
+	clone.ObjectMeta.UID = ""
+	clone.ObjectMeta.ResourceVersion = ""
+	clone.ObjectMeta.CreationTimestamp = metav1.Time{}
+	clone.Spec.NodeName = ""
+	return clone
+}
+
+// sortPodsByDescendingRequests sorts pods by the weighted sum of their requests relative to the total requests of the
+// pods. The resources are weighted with the default weights of resutil.EffectiveWeights like the waste when scoring.
+func sortPodsByDescendingRequests(pods []corev1.Pod) {
+	weights := resutil.EffectiveWeights(nil, pods)
+	totals := make(map[corev1.ResourceName]float64, len(weights))
+	for i := range pods {
+		for name, quantity := range resutil.PodRequests(&pods[i]) {
+			totals[name] += float64(quantity.MilliValue())
+		}
+	}
+	score := func(pod *corev1.Pod) float64 {
+		var sum float64
+		for name, quantity := range resutil.PodRequests(pod) {
+			if weights[name] > 0 && totals[name] > 0 {
+				sum += weights[name] * float64(quantity.MilliValue()) / totals[name]
+			}
+		}
+		return sum
+	}
+	slices.SortStableFunc(pods, func(i, j corev1.Pod) int {
+		return cmp.Compare(score(&j), score(&i))
+	})
+}
+
 func (a *access) AddPods(ctx context.Context, pods ...corev1.Pod) error {
 	for _, pod := range pods {
 		err := a.client.Create(ctx, &pod)
@@ -226,10 +234,68 @@ func (a *access) CreatePodsFromYaml(ctx context.Context, podYamlPath string, rep
 }
 
 func (a *access) KubeConfigPath() string {
+	if a.backend == InMemoryBackend {
+		return ""
+	}
 	return kubeConfigPath
 }
 
-func InitializeAccess(scheme *runtime.Scheme, binaryAssetsDir string, apiServerFlags map[string]string) (scalesim.VirtualClusterAccess, error) {
+// InitializeAccess initializes the virtual cluster using the given backend. The binaryAssetsDir and apiServerFlags are
+// only used by the EnvTestBackend.
+func InitializeAccess(scheme *runtime.Scheme, backend Backend, binaryAssetsDir string, apiServerFlags map[string]string) (scalesim.VirtualClusterAccess, error) {
+	switch backend {
+	case EnvTestBackend:
+		return initializeEnvTestAccess(scheme, binaryAssetsDir, apiServerFlags)
+	case InMemoryBackend:
+		access, err := initializeInMemoryAccess(scheme)
+		if err != nil {
+			return nil, err
+		}
+		return access, nil
+	default:
+		return nil, fmt.Errorf("unknown virtual cluster backend %q", backend)
+	}
+}
+
+func initializeInMemoryAccess(scheme *runtime.Scheme) (*access, error) {
+	inMemoryClient := newInMemoryClient(scheme)
+	access := &access{
+		backend:        InMemoryBackend,
+		client:         inMemoryClient,
+		referenceNodes: make(map[string]corev1.Node),
+	}
+	clientSet := fakeclientset.NewSimpleClientset()
+	if err := access.startScheduler(clientSet); err != nil {
+		return nil, err
+	}
+	inMemoryClient.addListener(mirrorStorageObjects(clientSet, access.scheduler.informerFactory))
+	inMemoryClient.addListener(func(_ context.Context, eventType watch.EventType, obj runtime.Object) {
+		access.scheduler.notify(eventType, obj)
+	})
+	slog.Info("initialized in-memory virtual cluster")
+	return access, nil
+}
+
+// startScheduler creates the in-process kube-scheduler and starts its scheduling loop, which stopScheduler stops. The
+// clientSet is only used by the informers of scheduler plugins that need objects other than nodes and pods. With the
+// envtest backend the scheduler watches nodes and pods in the API server until it is stopped.
+func (a *access) startScheduler(clientSet kubernetes.Interface) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := newScheduler(ctx, a.client, clientSet, a.backend)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("cannot create in-process kube-scheduler: %w", err)
+	}
+	a.scheduler = s
+	a.stopScheduler = cancel
+	go s.run(ctx)
+	if a.backend == EnvTestBackend {
+		s.watchObjects(ctx)
+	}
+	return nil
+}
+
+func initializeEnvTestAccess(scheme *runtime.Scheme, binaryAssetsDir string, apiServerFlags map[string]string) (scalesim.VirtualClusterAccess, error) {
 	env := &envtest.Environment{
 		Scheme:                   scheme,
 		BinaryAssetsDirectory:    binaryAssetsDir,
@@ -252,7 +318,7 @@ func InitializeAccess(scheme *runtime.Scheme, binaryAssetsDir string, apiServerF
 	if cfg == nil {
 		return nil, fmt.Errorf("got nil from envtest.environment.Start()")
 	}
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	k8sClient, err := client.NewWithWatch(cfg, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new client: %w", err)
 	}
@@ -263,13 +329,16 @@ func InitializeAccess(scheme *runtime.Scheme, binaryAssetsDir string, apiServerF
 	}
 	slog.Info("Wrote kubeconfig", "kubeconfig", kubeConfigPath)
 
-	schedulerProcess, err := StartScheduler(binaryAssetsDir)
 	access := &access{
-		client:               k8sClient,
-		restConfig:           cfg,
-		environment:          env,
-		kubeSchedulerProcess: schedulerProcess,
-		referenceNodes:       make(map[string]corev1.Node),
+		backend:        EnvTestBackend,
+		client:         k8sClient,
+		restConfig:     cfg,
+		environment:    env,
+		referenceNodes: make(map[string]corev1.Node),
+	}
+	clientSet, err := kubernetes.NewForConfig(cfg)
+	if err == nil {
+		err = access.startScheduler(clientSet)
 	}
 	if err != nil {
 		slog.Info("cannot start kube-scheduler.", "error", err)
@@ -280,19 +349,16 @@ func InitializeAccess(scheme *runtime.Scheme, binaryAssetsDir string, apiServerF
 }
 
 func (a *access) Shutdown() {
+	if a.stopScheduler != nil {
+		slog.Info("STOPPING in-process kube-scheduler")
+		a.stopScheduler()
+	}
+	if a.environment == nil {
+		return
+	}
 	slog.Info("STOPPING env test apiserver,etcd")
 	err := a.environment.Stop()
 	slog.Warn("error stopping envtest.", "error", err)
-	if a.kubeSchedulerProcess != nil { //TODO: launch kube-scheduler as part of simulator.
-		err = a.kubeSchedulerProcess.Signal(syscall.SIGTERM)
-		if err != nil {
-			slog.Warn("error signalling kubescheduler process.", "error", err)
-		}
-		slog.Info("waiting for kube-scheduler to exit...", "signal", syscall.SIGTERM.String())
-		procState, err := a.kubeSchedulerProcess.Wait()
-		slog.Info("kube-scheduler done", "exited", procState.Exited(), "exit-success", procState.Success(), "exit-code", procState.ExitCode(), "error", err)
-	}
-	return
 }
 
 func (a *access) AddNodesAndUpdateLabels(ctx context.Context, nodes ...*corev1.Node) error {
@@ -354,8 +420,49 @@ func (a *access) GetReferenceNode(instanceType string) (*corev1.Node, error) {
 	if refNode, ok := a.referenceNodes[instanceType]; ok {
 		return &refNode, nil
 	} else {
-		return nil, fmt.Errorf("no reference node matching instance type %s found", instanceType)
+		return nil, fmt.Errorf("%w for instance type %s", scalesim.ErrReferenceNodeNotFound, instanceType)
+	}
+}
+
+func (a *access) AddPriorityClasses(ctx context.Context, priorityClasses ...*schedulingv1.PriorityClass) error {
+	for _, pc := range priorityClasses {
+		pc = cloneForCopy(pc)
+		err := a.client.Create(ctx, pc)
+		if apierrors.IsAlreadyExists(err) {
+			existing := &schedulingv1.PriorityClass{}
+			if err = a.client.Get(ctx, client.ObjectKeyFromObject(pc), existing); err != nil {
+				return fmt.Errorf("cannot get priority class %q: %w", pc.Name, err)
+			}
+			if existing.Value == pc.Value && existing.GlobalDefault == pc.GlobalDefault && ptr.Equal(existing.PreemptionPolicy, pc.PreemptionPolicy) {
+				continue
+			}
+			// the value of a priority class is immutable, so it is replaced.
+			if err = a.client.Delete(ctx, existing); err != nil {
+				return fmt.Errorf("cannot replace priority class %q: %w", pc.Name, err)
+			}
+			err = a.client.Create(ctx, pc)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot add priority class %q: %w", pc.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *access) ListPriorityClasses(ctx context.Context) ([]schedulingv1.PriorityClass, error) {
+	priorityClassList := schedulingv1.PriorityClassList{}
+	if err := a.client.List(ctx, &priorityClassList); err != nil {
+		return nil, fmt.Errorf("cannot list priority classes: %w", err)
+	}
+	return priorityClassList.Items, nil
+}
+
+func (a *access) ListPodDisruptionBudgets(ctx context.Context) ([]policyv1.PodDisruptionBudget, error) {
+	pdbList := policyv1.PodDisruptionBudgetList{}
+	if err := a.client.List(ctx, &pdbList); err != nil {
+		return nil, fmt.Errorf("cannot list pod disruption budgets: %w", err)
 	}
+	return pdbList.Items, nil
 }
 
 func (a *access) ListNodes(ctx context.Context) ([]corev1.Node, error) {
@@ -565,12 +672,71 @@ func (a *access) ApplyK8sObject(ctx context.Context, k8sObjs ...runtime.Object)
 			if err != nil {
 				return err
 			}
+		case *corev1.Namespace, *storagev1.StorageClass, *corev1.PersistentVolume, *corev1.PersistentVolumeClaim, *policyv1.PodDisruptionBudget:
+			if err := a.replaceObject(ctx, obj.(client.Object)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceObject creates a copy of the object without finalizers, since no controller removes them in the virtual
+// cluster. An existing object of the same name is replaced unless it is a namespace, as many fields are immutable.
+func (a *access) replaceObject(ctx context.Context, obj client.Object) error {
+	obj = cloneForCopy(obj)
+	obj.SetFinalizers(nil)
+	key := client.ObjectKeyFromObject(obj)
+	err := a.client.Create(ctx, obj)
+	if apierrors.IsAlreadyExists(err) {
+		if _, ok := obj.(*corev1.Namespace); ok {
+			return nil
+		}
+		if err = a.deleteObject(ctx, obj); err != nil {
+			return err
+		}
+		err = a.client.Create(ctx, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot apply %T %s: %w", obj, key, err)
+	}
+	return nil
+}
+
+func (a *access) DeleteK8sObject(ctx context.Context, k8sObjs ...runtime.Object) error {
+	for _, obj := range k8sObjs {
+		if err := a.deleteObject(ctx, obj.(client.Object)); err != nil && !apierrors.IsNotFound(err) {
+			return err
 		}
 	}
 	return nil
 }
 
+// deleteObject deletes the object of the same name as the given one after removing its finalizers, which are added by
+// the admission plugins of the envtest kube-apiserver, e.g. to PersistentVolumeClaims.
+func (a *access) deleteObject(ctx context.Context, obj client.Object) error {
+	key := client.ObjectKeyFromObject(obj)
+	existing := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+	if err := a.client.Get(ctx, key, existing); err != nil {
+		return fmt.Errorf("cannot get %T %s: %w", obj, key, err)
+	}
+	if len(existing.GetFinalizers()) > 0 {
+		patch := client.MergeFrom(existing.DeepCopyObject().(client.Object))
+		existing.SetFinalizers(nil)
+		if err := a.client.Patch(ctx, existing, patch); err != nil {
+			return fmt.Errorf("cannot remove finalizers of %T %s: %w", obj, key, err)
+		}
+	}
+	if err := a.client.Delete(ctx, existing); err != nil {
+		return fmt.Errorf("cannot delete %T %s: %w", obj, key, err)
+	}
+	return nil
+}
+
 func (a *access) ClearAll(ctx context.Context) (err error) {
+	if a.backend == InMemoryBackend {
+		return a.clearAllInMemory(ctx)
+	}
 	// kubectl delete all --all
 	var errBuffer bytes.Buffer
 	delCmd := exec.Command("kubectl", "--kubeconfig", kubeConfigPath, "delete", "all", "--all")
@@ -707,18 +873,3 @@ func createKubeconfigFileForRestConfig(restConfig rest.Config) error {
 	}
 	return nil
 }
-
-func StartScheduler(binaryAssetsDir string) (*os.Process, error) {
-	workingDir, _ := os.Getwd()
-	schedulerConfigPath := workingDir + "/virtualcluster/scheduler-config.yaml"
-	command := exec.Command(binaryAssetsDir+"/kube-scheduler", "--kubeconfig", kubeConfigPath, "--config", schedulerConfigPath, "--leader-elect=false", "--v=3")
-	//command := exec.Command(binaryAssetsDir+"/kube-scheduler", "--kubeconfig", kubeConfigPath)
-	command.Stderr = os.Stderr
-	command.Stdout = os.Stdout
-	slog.Info("launching kube-scheduler", "command", command)
-	err := command.Start()
-	if err != nil {
-		return nil, fmt.Errorf("cannot start kube-scheduler: %w", err)
-	}
-	return command.Process, nil
-}
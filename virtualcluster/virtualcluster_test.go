@@ -0,0 +1,29 @@
+package virtualcluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSortPodsByDescendingRequests(t *testing.T) {
+	small := newTestPod("small", "100m")
+	medium := newTestPod("medium", "200m")
+	gpu := newTestPod("gpu", "100m")
+	gpu.Spec.Containers[0].Resources.Requests["nvidia.com/gpu"] = resource.MustParse("1")
+	initContainer := newTestPod("init", "100m")
+	initContainer.Spec.InitContainers = []corev1.Container{{
+		Name:      "init",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}},
+	}}
+
+	pods := []corev1.Pod{small, medium, gpu, initContainer}
+	sortPodsByDescendingRequests(pods)
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	assert.Equal(t, []string{"gpu", "init", "medium", "small"}, names)
+}
@@ -3,12 +3,15 @@ package webutil
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+
 	scalesim "github.com/elankath/scaler-simulator"
 )
 
@@ -43,7 +46,7 @@ func Log(w http.ResponseWriter, msg string) {
 }
 
 func Logf(w http.ResponseWriter, format string, a ...any) {
-	msg := fmt.Sprintf(format, a)
+	msg := fmt.Sprintf(format, a...)
 	Log(w, msg)
 }
 
@@ -57,6 +60,34 @@ func LogNodePodAssignments(w http.ResponseWriter, scenarioName string, nodePodAs
 	Log(w, sb.String())
 }
 
+// SlogWriter is a http.ResponseWriter for runs that are not driven by a request, e.g. in the background. Every line
+// written to it is logged with slog and the given attributes instead.
+type SlogWriter struct {
+	header http.Header
+	attrs  []any
+}
+
+var _ http.Flusher = (*SlogWriter)(nil)
+
+func NewSlogWriter(attrs ...any) *SlogWriter {
+	return &SlogWriter{header: make(http.Header), attrs: attrs}
+}
+
+func (w *SlogWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *SlogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimSpace(string(p)), "\n") {
+		slog.Info(line, w.attrs...)
+	}
+	return len(p), nil
+}
+
+func (w *SlogWriter) WriteHeader(int) {}
+
+func (w *SlogWriter) Flush() {}
+
 func HandleShootNameMissing(w http.ResponseWriter) {
 	http.Error(w, "shoot param empty", http.StatusBadRequest)
 }
@@ -69,6 +100,11 @@ func InternalError(w http.ResponseWriter, err error) {
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
 
+// BadRequest reports an invalid request, e.g. a query param that cannot be parsed.
+func BadRequest(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
 func GetIntPathParam(r *http.Request, name string, defVal int) int {
 	valstr := r.PathValue(name)
 	if valstr == "" {
@@ -108,6 +144,31 @@ func GetFloatQueryParam(r *http.Request, name string, defVal float64) (float64,
 	return val, nil
 }
 
+// GetResourceFactorsQueryParam parses a comma separated list of per-resource factors like `cpu:2,memory:0.5`. Factors
+// must be finite and not negative.
+func GetResourceFactorsQueryParam(r *http.Request, name string) (map[corev1.ResourceName]float64, error) {
+	valstr := r.URL.Query().Get(name)
+	if valstr == "" {
+		return nil, nil
+	}
+	weights := make(map[corev1.ResourceName]float64)
+	for _, entry := range strings.Split(valstr, ",") {
+		resourceName, weightStr, ok := strings.Cut(entry, ":")
+		if !ok || resourceName == "" {
+			return nil, fmt.Errorf("invalid resource factor %q in query param %q, expected <resource>:<factor>", entry, name)
+		}
+		weight, err := strconv.ParseFloat(weightStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid factor of resource %q in query param %q: %w", resourceName, name, err)
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid factor %q of resource %q in query param %q, expected a finite non-negative number", weightStr, resourceName, name)
+		}
+		weights[corev1.ResourceName(resourceName)] = weight
+	}
+	return weights, nil
+}
+
 func GetStringQueryParam(r *http.Request, name, defVal string) string {
 	val := r.URL.Query().Get(name)
 	if val == "" {
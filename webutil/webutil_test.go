@@ -0,0 +1,22 @@
+package webutil
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetResourceFactorsQueryParam(t *testing.T) {
+	r := httptest.NewRequest("POST", "/scenarios/score5?resourceWeights=cpu:2,memory:0.5", nil)
+	weights, err := GetResourceFactorsQueryParam(r, "resourceWeights")
+	assert.Nil(t, err)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 2, corev1.ResourceMemory: 0.5}, weights)
+
+	for _, invalid := range []string{"cpu", "cpu:x", "cpu:-1", "cpu:NaN", "cpu:Inf", "memory:-Inf"} {
+		r = httptest.NewRequest("POST", "/scenarios/score5?resourceWeights="+invalid, nil)
+		_, err = GetResourceFactorsQueryParam(r, "resourceWeights")
+		assert.Error(t, err, invalid)
+	}
+}